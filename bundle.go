@@ -0,0 +1,332 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오전 10:10
+ */
+
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// BundleItem far 에 함께 배포할 플랫폼별 바이너리
+// Path 에는 {{.Os}}, {{.Arch}} 를 사용할 수 있다 (프로젝트 base 디렉토리 기준 또는 절대경로)
+type BundleItem struct {
+	Path string `yaml:"path"`
+	// Name far 의 platform/<os>_<arch> 디렉토리에 저장될 이름. 지정하지 않으면 Path 의 파일명을 사용한다
+	Name string `yaml:"name,omitempty"`
+	// Optional 파일이 없는 플랫폼은 건너뛴다
+	Optional bool `yaml:"optional,omitempty"`
+}
+
+// resolvePath 플랫폼에 해당하는 파일 경로를 구한다
+func (i BundleItem) resolvePath(baseDir string, platform PlatformItem) (string, error) {
+	tmpl, err := template.New("bundle").Option("missingkey=error").Parse(i.Path)
+	if err != nil {
+		return "", fmt.Errorf("invalid bundle path %s : %s", i.Path, err.Error())
+	}
+
+	var buff bytes.Buffer
+	err = tmpl.Execute(&buff, platform)
+	if err != nil {
+		return "", fmt.Errorf("invalid bundle path %s : %s", i.Path, err.Error())
+	}
+
+	path := buff.String()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	return path, nil
+}
+
+// loadBundles 프로젝트 설정의 bundle 항목들을 빌드 대상 플랫폼별로 platform/<os>_<arch> 디렉토리에 복사한다
+func (b *BuildContext) loadBundles() error {
+	if len(b.project.Bundle) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n>> copying bundle binaries...\n")
+	total := 0
+	for _, platform := range buildPlatformList.GetBuildPlatforms() {
+		targetDir := filepath.Join(b.workingDir, PlatformDirName, platform.getPlatformDirectory())
+		for _, item := range b.project.Bundle {
+			source, err := item.resolvePath(b.ProjectBaseDir, platform)
+			if err != nil {
+				return err
+			}
+
+			if CheckFileExist(source) != nil {
+				if item.Optional {
+					fmt.Printf("skip : %s (not found)\n", source)
+					continue
+				}
+				return fmt.Errorf("bundle %s not found for %s", source, platform.getPlatformDirectory())
+			}
+
+			err = verifyBinaryPlatform(source, platform)
+			if err != nil {
+				return fmt.Errorf("invalid bundle %s : %s", source, err.Error())
+			}
+
+			name := item.Name
+			if len(name) == 0 {
+				name = filepath.Base(source)
+			}
+			target := filepath.Join(targetDir, name)
+			if _, err := os.Lstat(target); err == nil {
+				return fmt.Errorf("bundle %s conflicts with %s", source, filepath.Join(PlatformDirName, platform.getPlatformDirectory(), name))
+			}
+
+			err = EnsureDirectory(targetDir)
+			if err != nil {
+				return err
+			}
+			err = CopyFile(source, target)
+			if err != nil {
+				return fmt.Errorf("fail to copy bundle %s : %s", source, err.Error())
+			}
+			_ = os.Chmod(target, 0755)
+			total++
+		}
+	}
+
+	fmt.Printf("total %d bundle binaries copied...\n", total)
+	return nil
+}
+
+// verifyBinaryPlatform 실행 파일이 대상 플랫폼용인지 ELF, Mach-O, PE 헤더로 확인한다
+// shebang 으로 시작하는 스크립트는 플랫폼에 무관하므로 검사하지 않는다
+func verifyBinaryPlatform(path string, platform PlatformItem) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	magic := make([]byte, 4)
+	_, err = io.ReadFull(file, magic)
+	if err != nil {
+		return fmt.Errorf("unknown executable format")
+	}
+
+	if bytes.HasPrefix(magic, []byte("#!")) {
+		return nil
+	}
+
+	expectedFormat := executableFormat(platform)
+	if len(expectedFormat) == 0 {
+		// plan9, aix 등 헤더를 확인할 수 없는 플랫폼
+		return nil
+	}
+
+	var formatName, archName string
+	switch {
+	case bytes.Equal(magic, []byte(wasmMagic)):
+		formatName = "wasm"
+		archName = "wasm"
+	case bytes.Equal(magic, []byte(elf.ELFMAG)):
+		formatName = "elf"
+		archName, err = elfArch(path)
+		if err == nil {
+			err = verifyElfOsAbi(path, platform.Os)
+		}
+	case bytes.HasPrefix(magic, []byte("MZ")):
+		formatName = "pe"
+		archName, err = peArch(path)
+	default:
+		formatName = "macho"
+		var archList []string
+		archList, err = machoArchList(path)
+		if err != nil {
+			return fmt.Errorf("unknown executable format")
+		}
+		for _, a := range archList {
+			if a == platform.Arch {
+				archName = a
+				break
+			}
+		}
+		if len(archName) == 0 {
+			archName = strings.Join(archList, ",")
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if formatName != expectedFormat {
+		return fmt.Errorf("%s executable is not for %s (expected %s)", formatName, platform.Os, expectedFormat)
+	}
+	if archName != platform.Arch {
+		return fmt.Errorf("%s executable is not for %s (expected %s)", archName, platform.Arch, platform.Arch)
+	}
+	return nil
+}
+
+const wasmMagic = "\x00asm"
+
+// executableFormat 플랫폼별 실행 파일 형식. 확인할 수 없는 형식이면 빈 문자열을 반환한다
+func executableFormat(platform PlatformItem) string {
+	if platform.Arch == "wasm" {
+		return "wasm"
+	}
+	switch platform.Os {
+	case "darwin", "ios":
+		return "macho"
+	case "windows":
+		return "pe"
+	case "plan9", "aix":
+		return ""
+	}
+	return "elf"
+}
+
+func elfArch(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid elf : %s", err.Error())
+	}
+	defer f.Close()
+
+	switch f.Machine {
+	case elf.EM_X86_64:
+		return "amd64", nil
+	case elf.EM_386:
+		return "386", nil
+	case elf.EM_AARCH64:
+		return "arm64", nil
+	case elf.EM_ARM:
+		return "arm", nil
+	case elf.EM_RISCV:
+		return "riscv64", nil
+	case elf.EM_S390:
+		return "s390x", nil
+	case elf.EM_LOONGARCH:
+		return "loong64", nil
+	case elf.EM_PPC64:
+		if f.Data == elf.ELFDATA2LSB {
+			return "ppc64le", nil
+		}
+		return "ppc64", nil
+	case elf.EM_MIPS:
+		suffix := ""
+		if f.Data == elf.ELFDATA2LSB {
+			suffix = "le"
+		}
+		if f.Class == elf.ELFCLASS64 {
+			return "mips64" + suffix, nil
+		}
+		return "mips" + suffix, nil
+	}
+	return f.Machine.String(), nil
+}
+
+// elfOsAbiList ELF 헤더의 OSABI 값으로 확인할 수 있는 os 목록
+// go 링커는 freebsd, netbsd, openbsd 바이너리에만 OSABI 를 기록하며, 그 외(linux 등)는 ELFOSABI_NONE 이므로 os 를 확인할 수 없다
+var elfOsAbiList = map[elf.OSABI][]string{
+	elf.ELFOSABI_FREEBSD: {"freebsd"},
+	elf.ELFOSABI_NETBSD:  {"netbsd"},
+	elf.ELFOSABI_OPENBSD: {"openbsd"},
+	elf.ELFOSABI_LINUX:   {"linux", "android"},
+	elf.ELFOSABI_SOLARIS: {"solaris", "illumos"},
+}
+
+// verifyElfOsAbi ELF 헤더의 OSABI 가 특정 os 를 나타내면 대상 os 와 일치하는지 확인한다
+// freebsd 는 모든 실행 파일에 OSABI 가 기록되므로 ELFOSABI_NONE 인 경우도 다른 os 용 바이너리로 판단한다
+func verifyElfOsAbi(path, goos string) error {
+	f, err := elf.Open(path)
+	if err != nil {
+		return fmt.Errorf("invalid elf : %s", err.Error())
+	}
+	defer f.Close()
+
+	osList, ok := elfOsAbiList[f.OSABI]
+	if !ok {
+		if goos == "freebsd" && f.OSABI == elf.ELFOSABI_NONE {
+			return fmt.Errorf("elf executable (OSABI %s) is not for %s", f.OSABI.String(), goos)
+		}
+		return nil
+	}
+	for _, name := range osList {
+		if name == goos {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s elf executable is not for %s (OSABI %s)", strings.Join(osList, ","), goos, f.OSABI.String())
+}
+
+func peArch(path string) (string, error) {
+	f, err := pe.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid pe : %s", err.Error())
+	}
+	defer f.Close()
+
+	switch f.Machine {
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return "amd64", nil
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return "386", nil
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return "arm64", nil
+	case pe.IMAGE_FILE_MACHINE_ARMNT:
+		return "arm", nil
+	}
+	return fmt.Sprintf("machine(0x%x)", f.Machine), nil
+}
+
+func machoArchList(path string) ([]string, error) {
+	cpuList := make([]macho.Cpu, 0)
+	if fat, err := macho.OpenFat(path); err == nil {
+		for _, arch := range fat.Arches {
+			cpuList = append(cpuList, arch.Cpu)
+		}
+		fat.Close()
+	} else {
+		f, err := macho.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		cpuList = append(cpuList, f.Cpu)
+		f.Close()
+	}
+
+	archList := make([]string, 0, len(cpuList))
+	for _, cpu := range cpuList {
+		switch cpu {
+		case macho.CpuAmd64:
+			archList = append(archList, "amd64")
+		case macho.CpuArm64:
+			archList = append(archList, "arm64")
+		case macho.Cpu386:
+			archList = append(archList, "386")
+		default:
+			archList = append(archList, cpu.String())
+		}
+	}
+	return archList, nil
+}
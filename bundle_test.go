@@ -0,0 +1,93 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오전 10:40
+ */
+
+package main
+
+import (
+	"debug/elf"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestVerifyBinaryPlatform(t *testing.T) {
+	// 테스트 바이너리 자체는 로컬 플랫폼용 실행 파일이다
+	executable, err := os.Executable()
+	assert.Nil(t, err)
+
+	local := PlatformItem{Os: runtime.GOOS, Arch: runtime.GOARCH}
+	assert.Nil(t, verifyBinaryPlatform(executable, local))
+
+	other := PlatformItem{Os: runtime.GOOS, Arch: "s390x"}
+	assert.NotNil(t, verifyBinaryPlatform(executable, other))
+	assert.NotNil(t, verifyBinaryPlatform(executable, PlatformItem{Os: "windows", Arch: runtime.GOARCH}))
+
+	script := filepath.Join(t.TempDir(), "helper.sh")
+	assert.Nil(t, os.WriteFile(script, []byte("#!/bin/sh\necho hi\n"), 0755))
+	assert.Nil(t, verifyBinaryPlatform(script, other))
+
+	text := filepath.Join(t.TempDir(), "helper")
+	assert.Nil(t, os.WriteFile(text, []byte("hello world"), 0755))
+	assert.NotNil(t, verifyBinaryPlatform(text, local))
+}
+
+func TestVerifyElfOsAbi(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("elf executable required")
+	}
+	executable, err := os.Executable()
+	assert.Nil(t, err)
+	data, err := os.ReadFile(executable)
+	assert.Nil(t, err)
+
+	// 리눅스 바이너리는 ELFOSABI_NONE 이므로 os 를 확인할 수 없지만 freebsd 는 OSABI 가 반드시 기록된다
+	linuxBin := filepath.Join(t.TempDir(), "hello")
+	assert.Nil(t, os.WriteFile(linuxBin, data, 0755))
+	assert.Nil(t, verifyBinaryPlatform(linuxBin, PlatformItem{Os: "netbsd", Arch: runtime.GOARCH}))
+	assert.NotNil(t, verifyBinaryPlatform(linuxBin, PlatformItem{Os: "freebsd", Arch: runtime.GOARCH}))
+
+	data[elf.EI_OSABI] = byte(elf.ELFOSABI_FREEBSD)
+	freebsdBin := filepath.Join(t.TempDir(), "hello")
+	assert.Nil(t, os.WriteFile(freebsdBin, data, 0755))
+	assert.Nil(t, verifyBinaryPlatform(freebsdBin, PlatformItem{Os: "freebsd", Arch: runtime.GOARCH}))
+	err = verifyBinaryPlatform(freebsdBin, PlatformItem{Os: "linux", Arch: runtime.GOARCH})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "freebsd elf executable is not for linux")
+}
+
+func TestBundleResolvePath(t *testing.T) {
+	item := BundleItem{Path: "third_party/{{.Os}}_{{.Arch}}/jq"}
+	path, err := item.resolvePath("/project", PlatformItem{Os: "linux", Arch: "arm64"})
+	assert.Nil(t, err)
+	assert.Equal(t, "/project/third_party/linux_arm64/jq", path)
+
+	item = BundleItem{Path: "third_party/{{.Unknown}}/jq"}
+	_, err = item.resolvePath("/project", PlatformItem{Os: "linux", Arch: "arm64"})
+	assert.NotNil(t, err)
+}
+
+func TestVerifyWasmBinary(t *testing.T) {
+	wasm := filepath.Join(t.TempDir(), "hello.wasm")
+	assert.Nil(t, os.WriteFile(wasm, []byte("\x00asm\x01\x00\x00\x00"), 0644))
+	assert.Nil(t, verifyBinaryPlatform(wasm, PlatformItem{Os: "js", Arch: "wasm"}))
+	assert.NotNil(t, verifyBinaryPlatform(wasm, PlatformItem{Os: "linux", Arch: "amd64"}))
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 3:05
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// projectWideFiles 변경되면 모든 프로세스에 영향을 주는 파일
+var projectWideFiles = []string{goModFilename, "go.sum", ProjectConfigFile, farIgnoreFilename}
+
+// changedFilesSince HEAD 와 rev 의 merge base 로부터 HEAD 까지 변경된 파일 목록을 구한다 (rev...HEAD)
+// 결과는 저장소 루트로부터의 상대경로이다
+func changedFilesSince(repoDir, rev string) ([]string, error) {
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return nil, fmt.Errorf("fail to open git %s : %s", repoDir, err.Error())
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("fail to read HEAD : %s", err.Error())
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("fail to read HEAD commit : %s", err.Error())
+	}
+
+	baseHash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("fail to resolve %s : %s", rev, err.Error())
+	}
+	baseCommit, err := repo.CommitObject(*baseHash)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read %s commit : %s", rev, err.Error())
+	}
+
+	bases, err := baseCommit.MergeBase(headCommit)
+	if err != nil {
+		return nil, fmt.Errorf("fail to find merge base of %s : %s", rev, err.Error())
+	}
+	if len(bases) > 0 {
+		baseCommit = bases[0]
+	}
+
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(baseTree, headTree)
+	if err != nil {
+		return nil, fmt.Errorf("fail to diff %s...HEAD : %s", rev, err.Error())
+	}
+
+	fileSet := make(map[string]struct{})
+	for _, change := range changes {
+		// 이동된 파일은 이전 경로와 새 경로 모두 변경된 것으로 본다
+		if len(change.From.Name) > 0 {
+			fileSet[change.From.Name] = struct{}{}
+		}
+		if len(change.To.Name) > 0 {
+			fileSet[change.To.Name] = struct{}{}
+		}
+	}
+
+	fileList := make([]string, 0, len(fileSet))
+	for name := range fileSet {
+		fileList = append(fileList, filepath.FromSlash(name))
+	}
+	sort.Strings(fileList)
+	return fileList, nil
+}
+
+// listDependencies go list -deps 로 main 패키지들이 의존하는 패키지 중 표준 라이브러리가 아닌 패키지의 디렉토리 목록과
+// 해당 패키지들이 //go:embed 로 포함하는 파일(절대경로) 목록을 구한다
+func listDependencies(baseDir string, cmdList []CmdRecord) (map[string]struct{}, map[string]struct{}, error) {
+	dirSet := make(map[string]struct{})
+	embedSet := make(map[string]struct{})
+	if len(cmdList) == 0 {
+		return dirSet, embedSet, nil
+	}
+
+	// 패키지별로 한 줄에 디렉토리와 embed 파일들을 탭으로 구분하여 출력한다
+	out, err := goListDeps(baseDir, cmdList, "{{if not .Standard}}{{.Dir}}{{range .EmbedFiles}}\t{{.}}{{end}}{{end}}")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		dir := fields[0]
+		if len(dir) == 0 {
+			continue
+		}
+		dirSet[dir] = struct{}{}
+		for _, embed := range fields[1:] {
+			embedSet[filepath.Join(dir, filepath.FromSlash(embed))] = struct{}{}
+		}
+	}
+	return dirSet, embedSet, nil
+}
+
+// goListDeps baseDir 에서 main 패키지들에 대해 go list -deps -f format 을 실행한다
+func goListDeps(baseDir string, cmdList []CmdRecord, format string) (string, error) {
+	args := []string{"list", "-deps", "-f", format}
+	for _, record := range cmdList {
+		rel, err := filepath.Rel(baseDir, record.Path)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, "./"+filepath.ToSlash(rel))
+	}
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = baseDir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("go list -deps error : %s\n%s", err.Error(), stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// AffectedReason 변경된 파일(절대경로) 목록 중 프로세스에 영향을 주는 첫번째 파일과 사유를 구한다
+// 영향을 주는 파일이 없으면 빈 문자열을 반환한다
+func (b *BuildContext) AffectedReason(changedFiles []string) (string, error) {
+	wideFiles := make([]string, 0)
+	for _, name := range projectWideFiles {
+		wideFiles = append(wideFiles, filepath.Join(b.ProjectBaseDir, name))
+	}
+	if b.workspace != nil {
+		wideFiles = append(wideFiles, b.workspace.File, b.workspace.File+".sum")
+	}
+	for _, file := range changedFiles {
+		for _, wideFile := range wideFiles {
+			if file == wideFile {
+				return fmt.Sprintf("%s changed", b.relativePath(file)), nil
+			}
+		}
+	}
+
+	depDirs, embedFiles, err := listDependencies(b.ProjectBaseDir, b.ProcessList)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range changedFiles {
+		if _, ok := embedFiles[file]; ok {
+			return fmt.Sprintf("embedded file %s changed", b.relativePath(file)), nil
+		}
+		if filepath.Ext(file) != ".go" {
+			continue
+		}
+		if _, ok := depDirs[filepath.Dir(file)]; ok {
+			return fmt.Sprintf("go source %s changed", b.relativePath(file)), nil
+		}
+	}
+
+	if len(b.ResourceDir) > 0 {
+		for _, file := range changedFiles {
+			if isSubPath(b.ResourceDir, file) {
+				return fmt.Sprintf("resource %s changed", b.relativePath(file)), nil
+			}
+		}
+	}
+
+	resourceSet := make(map[string]struct{})
+	resourceList, err := b.ListResourceFiles()
+	if err != nil {
+		return "", err
+	}
+	for _, resource := range resourceList {
+		resourceSet[resource.Source] = struct{}{}
+	}
+	for _, item := range b.project.Bundle {
+		for _, platform := range buildPlatformList.GetBuildPlatforms() {
+			path, err := item.resolvePath(b.ProjectBaseDir, platform)
+			if err != nil {
+				return "", err
+			}
+			resourceSet[path] = struct{}{}
+		}
+	}
+
+	for _, file := range changedFiles {
+		if _, ok := resourceSet[file]; ok {
+			return fmt.Sprintf("resource %s changed", b.relativePath(file)), nil
+		}
+	}
+	return "", nil
+}
+
+// relativePath 출력용으로 프로젝트 base 디렉토리로부터의 상대경로를 구한다
+func (b *BuildContext) relativePath(path string) string {
+	rel, err := filepath.Rel(b.ProjectBaseDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// filterChangedProcesses rev 이후 변경된 파일에 영향을 받는 프로세스만 골라낸다
+func filterChangedProcesses(procNames []string, rev string) ([]string, error) {
+	var changedFiles []string
+	affected := make([]string, 0, len(procNames))
+	for _, procName := range procNames {
+		ctx, err := NewBuildContext(procName)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %s", procName, err.Error())
+		}
+		ctx.Profile = profileName
+
+		if changedFiles == nil {
+			if !ctx.GitSupport {
+				return nil, fmt.Errorf("changed-since requires git repository")
+			}
+			fileList, err := changedFilesSince(ctx.gitBaseDir, rev)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Printf("\n>> %d files changed since %s\n", len(fileList), rev)
+			changedFiles = make([]string, 0, len(fileList))
+			for _, file := range fileList {
+				fmt.Printf("  %s\n", file)
+				changedFiles = append(changedFiles, filepath.Join(ctx.gitBaseDir, file))
+			}
+		}
+
+		reason, err := ctx.AffectedReason(changedFiles)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %s", procName, err.Error())
+		}
+		if len(reason) == 0 {
+			fmt.Printf("%s : not affected. skip\n", procName)
+			continue
+		}
+		fmt.Printf("%s : affected (%s)\n", procName, reason)
+		affected = append(affected, procName)
+	}
+	return affected, nil
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 3:40
+ */
+
+package main
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func commitTestSources(t *testing.T, repo *git.Repository, baseDir string, files map[string]string) plumbing.Hash {
+	writeTestSources(t, baseDir, files)
+	wt, err := repo.Worktree()
+	assert.Nil(t, err)
+	assert.Nil(t, wt.AddGlob("."))
+	hash, err := wt.Commit("test", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	assert.Nil(t, err)
+	return hash
+}
+
+func TestChangedProcesses(t *testing.T) {
+	mainSource := "package main\n\nimport _ \"example.com/hello/internal/api\"\n\nfunc main() {}\n"
+	baseDir := t.TempDir()
+	repo, err := git.PlainInit(baseDir, false)
+	assert.Nil(t, err)
+
+	base := commitTestSources(t, repo, baseDir, map[string]string{
+		"go.mod":                 "module example.com/hello\n\ngo 1.16\n",
+		"cmd/api/main.go":        mainSource,
+		"cmd/worker/main.go":     "package main\n\nfunc main() {}\n",
+		"internal/api/api.go":    "package api\n",
+		"conf/api.yaml":          "port: 80\n",
+		"internal/other/doc.go":  "package other\n",
+		"internal/other/doc.txt": "doc\n",
+	})
+	commitTestSources(t, repo, baseDir, map[string]string{
+		"internal/api/api.go": "package api\n\nconst Version = 2\n",
+	})
+
+	changed, err := changedFilesSince(baseDir, base.String())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join("internal", "api", "api.go")}, changed)
+
+	records, err := discoverMainPackages(baseDir)
+	assert.Nil(t, err)
+	ctx := &BuildContext{ProjectBaseDir: baseDir, mainPackages: records, ProcessList: records}
+
+	assert.Nil(t, ctx.SelectBinaries([]string{"worker"}))
+	changedPath := []string{filepath.Join(baseDir, changed[0])}
+	reason, err := ctx.AffectedReason(changedPath)
+	assert.Nil(t, err)
+	assert.Empty(t, reason)
+
+	assert.Nil(t, ctx.SelectBinaries([]string{"api"}))
+	reason, err = ctx.AffectedReason(changedPath)
+	assert.Nil(t, err)
+	assert.Contains(t, reason, "api.go")
+
+	assert.Nil(t, ctx.SelectBinaries([]string{"worker"}))
+	reason, err = ctx.AffectedReason([]string{filepath.Join(baseDir, "conf", "api.yaml")})
+	assert.Nil(t, err)
+	assert.Contains(t, reason, "api.yaml")
+
+	reason, err = ctx.AffectedReason([]string{filepath.Join(baseDir, "go.sum")})
+	assert.Nil(t, err)
+	assert.Contains(t, reason, "go.sum")
+}
+
+func TestAffectedReasonEmbedFile(t *testing.T) {
+	baseDir := t.TempDir()
+	writeTestSources(t, baseDir, map[string]string{
+		"go.mod":                         "module example.com/hello\n\ngo 1.16\n",
+		"cmd/api/main.go":                "package main\n\nimport _ \"example.com/hello/internal/web\"\n\nfunc main() {}\n",
+		"cmd/worker/main.go":             "package main\n\nfunc main() {}\n",
+		"internal/web/web.go":            "package web\n\nimport _ \"embed\"\n\n//go:embed static/index.html\nvar Index string\n",
+		"internal/web/static/index.html": "<html></html>\n",
+	})
+
+	records, err := discoverMainPackages(baseDir)
+	assert.Nil(t, err)
+	ctx := &BuildContext{ProjectBaseDir: baseDir, mainPackages: records, ProcessList: records}
+	changed := []string{filepath.Join(baseDir, "internal", "web", "static", "index.html")}
+
+	assert.Nil(t, ctx.SelectBinaries([]string{"api"}))
+	reason, err := ctx.AffectedReason(changed)
+	assert.Nil(t, err)
+	assert.Contains(t, reason, "index.html")
+
+	assert.Nil(t, ctx.SelectBinaries([]string{"worker"}))
+	reason, err = ctx.AffectedReason(changed)
+	assert.Nil(t, err)
+	assert.Empty(t, reason)
+}
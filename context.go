@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -33,16 +34,21 @@ import (
 )
 
 const (
-	resourceDirname = "resources"
-	cmdDirname      = "cmd"
-	gitDirname      = ".git"
-	gitConfigfile   = "config"
-	procTypeGeneral = "GENERAL"
-	procTypeUI      = "USER_INTERACTIVE"
+	resourceDirname    = "resources"
+	cmdDirname         = "cmd"
+	gitDirname         = ".git"
+	gitConfigfile      = "config"
+	deploymentFilename = "deployment.json"
+	procTypeGeneral    = "GENERAL"
+	procTypeUI         = "USER_INTERACTIVE"
+	packageModeBinary  = "binary"
+	packageModeScript  = "script"
 )
 
 type CmdRecord struct {
-	Path string
+	Path       string
+	ImportPath string
+	MainFile   string
 }
 
 func (c CmdRecord) GetBinaryname() string {
@@ -50,6 +56,9 @@ func (c CmdRecord) GetBinaryname() string {
 }
 
 func (c CmdRecord) GetMainSourcePath() string {
+	if len(c.MainFile) > 0 {
+		return c.MainFile
+	}
 	return filepath.Join(c.Path, fmt.Sprintf("%s.go", c.GetBinaryname()))
 }
 
@@ -59,9 +68,20 @@ type BuildContext struct {
 	ProcessList       []CmdRecord
 	GitSupport        bool
 	ExposeProcessName string
+	Profile           string
 	workingDir        string
 	procType          string
 	farPath           string
+	project           ProjectConfig
+	packageMode       string
+	mainPackages      []CmdRecord
+	binarySelected    bool
+	scheduler         *BuildScheduler
+	gitBaseDir        string
+	workspace         *GoWorkspace
+	workspaceModule   WorkspaceModule
+	buildInfo         map[string]interface{}
+	platformResources map[string]struct{}
 }
 
 func (b BuildContext) Print() {
@@ -72,6 +92,18 @@ func (b BuildContext) Print() {
 	fmt.Printf("project base dir : %s\n", b.ProjectBaseDir)
 	fmt.Printf("resource dir : %s\n", b.ResourceDir)
 	fmt.Printf("expose process name : %s\n", b.ExposeProcessName)
+	if len(b.Profile) > 0 {
+		fmt.Printf("profile : %s\n", b.Profile)
+	}
+
+	platformList := ""
+	for i, platform := range buildPlatformList.GetBuildPlatforms() {
+		if i > 0 {
+			platformList = platformList + ","
+		}
+		platformList = platformList + platform.getPlatformDirectory()
+	}
+	fmt.Printf("platform : %s\n", platformList)
 
 	if len(b.ProcessList) > 0 {
 		binList := ""
@@ -83,6 +115,8 @@ func (b BuildContext) Print() {
 			}
 		}
 		fmt.Printf("binary : %s\n", binList)
+	} else if b.packageMode == packageModeScript {
+		fmt.Printf("binary : none (%s package)\n", packageModeScript)
 	} else {
 		fmt.Printf("binary process : %s\n", b.ExposeProcessName)
 	}
@@ -115,6 +149,11 @@ func (b *BuildContext) Packaging() error {
 		return err
 	}
 
+	err = b.checkResourceSecrets()
+	if err != nil {
+		return err
+	}
+
 	err = b.compress()
 	if err != nil {
 		return err
@@ -137,7 +176,12 @@ func getGOPath() string {
 }
 
 func (b *BuildContext) compress() error {
-	farDir := filepath.Join(getGOPath(), "far", b.ExposeProcessName)
+	if len(b.farPath) == 0 {
+		farName := b.farName()
+		b.farPath = filepath.Join(getGOPath(), "far", b.ExposeProcessName, farName)
+	}
+
+	farDir := filepath.Dir(b.farPath)
 	fmt.Printf("\n>> compress to %s\n", farDir)
 
 	err := EnsureDirectory(farDir)
@@ -145,8 +189,6 @@ func (b *BuildContext) compress() error {
 		return fmt.Errorf("fail to prepare far dir : %s", err.Error())
 	}
 
-	farName := fmt.Sprintf("%s.far", b.ExposeProcessName)
-	b.farPath = filepath.Join(farDir, farName)
 	err = ZipArtifact(b.workingDir, b.farPath)
 	if err != nil {
 		return fmt.Errorf("fail to compress : %s", err.Error())
@@ -165,7 +207,55 @@ func (b *BuildContext) createDeployment() error {
 	m := make(map[string]interface{})
 	m["process"] = b.ExposeProcessName
 	m["process_type"] = b.procType
+	if b.packageMode == packageModeScript {
+		m["package"] = b.packageMode
+		// script 패키지는 실행할 go 바이너리가 없으므로 process_type 은 명시적으로 지정했거나 UI 프로세스인 경우에만 기록한다
+		if len(b.project.Process.Type) == 0 && b.procType == procTypeGeneral {
+			delete(m, "process_type")
+		}
+	}
+	if b.binarySelected && b.packageMode != packageModeScript {
+		binaries := make([]string, 0, len(b.ProcessList))
+		for _, record := range b.ProcessList {
+			binaries = append(binaries, record.GetBinaryname())
+		}
+		m["binaries"] = binaries
+	}
+	if len(b.Profile) > 0 {
+		m["profile"] = b.Profile
+	}
 
+	platforms := make([]string, 0)
+	for _, platform := range buildPlatformList.GetBuildPlatforms() {
+		platforms = append(platforms, platform.getPlatformDirectory())
+	}
+	m["platforms"] = platforms
+	if len(platformFilter) > 0 {
+		m["platform_filter"] = platformFilter
+	}
+
+	build := b.getBuildInfo()
+	if b.GitSupport {
+		gitInfo := readGitInfo(b.gitBaseDir)
+		if gitInfo.Valid {
+			build["git"] = gitInfo.ToMap()
+		}
+	}
+	m["build"] = build
+
+	if b.workspace != nil {
+		workspace, err := b.workspaceInfo()
+		if err != nil {
+			return fmt.Errorf("fail to read workspace modules : %s", err.Error())
+		}
+		m["workspace"] = workspace
+	}
+
+	return writeDeployment(b.workingDir, m)
+}
+
+// newBuildInfo deployment.json 의 build 항목(빌드 시각, 빌드 사용자)을 생성한다
+func newBuildInfo() map[string]interface{} {
 	build := make(map[string]interface{})
 	zoneName, _ := time.Now().Zone()
 	build["time"] = time.Now().Format(yyyyMMddHHmmss) + " " + zoneName
@@ -176,22 +266,28 @@ func (b *BuildContext) createDeployment() error {
 		user = "unknown"
 	}
 	build["user"] = strings.TrimSpace(user)
-	if b.GitSupport {
-		gitInfo := readGitInfo(b.ProjectBaseDir)
-		if gitInfo.Valid {
-			build["git"] = gitInfo.ToMap()
-		}
+	return build
+}
+
+// getBuildInfo 패키징 중 한번 생성한 빌드 정보를 리턴한다
+// 템플릿 변수(build.time, build.user)와 deployment.json 의 build 항목이 같은 값을 사용하도록 한다
+func (b *BuildContext) getBuildInfo() map[string]interface{} {
+	if b.buildInfo == nil {
+		b.buildInfo = newBuildInfo()
 	}
-	m["build"] = build
+	return b.buildInfo
+}
+
+func writeDeployment(workingDir string, m map[string]interface{}) error {
 	dat, err := json.Marshal(m)
 	if err != nil {
 		return fmt.Errorf("fail to create deployment : %s", err.Error())
 	}
 
-	depfile := filepath.Join(b.workingDir, "deployment.json")
+	depfile := filepath.Join(workingDir, deploymentFilename)
 	err = os.WriteFile(depfile, dat, 0644)
 	if err != nil {
-		return fmt.Errorf("fail to write deployment.json : %s", err.Error())
+		return fmt.Errorf("fail to write %s : %s", deploymentFilename, err.Error())
 	}
 
 	return nil
@@ -204,62 +300,216 @@ func (b *BuildContext) prepareResource() error {
 		return err
 	}
 
-	// determine proc type
-	uiProcXml := filepath.Join(b.workingDir, fmt.Sprintf("%s.ui.xml", b.ExposeProcessName))
-	err = CheckFileExist(uiProcXml)
-	if err == nil {
-		// exist ui xml
-		b.procType = procTypeUI
+	err = b.renderResourceTemplates()
+	if err != nil {
+		return err
 	}
 
-	return nil
+	err = b.checkShellScripts()
+	if err != nil {
+		return err
+	}
+
+	err = b.validateProperties()
+	if err != nil {
+		return err
+	}
+
+	err = b.validateStructuredResources()
+	if err != nil {
+		return err
+	}
+
+	b.procType, err = b.resolveProcType()
+	return err
+}
+
+// determineProcType <process>.ui.xml 파일의 존재 여부로 프로세스 타입을 결정한다
+func determineProcType(workingDir, procName string) string {
+	uiProcXml := filepath.Join(workingDir, fmt.Sprintf("%s.ui.xml", procName))
+	if CheckFileExist(uiProcXml) == nil {
+		// exist ui xml
+		return procTypeUI
+	}
+	return procTypeGeneral
 }
 
 func (b *BuildContext) loadResourceFiles() error {
+	err := b.checkProfile()
+	if err != nil {
+		return err
+	}
+
 	if len(b.ResourceDir) == 0 {
-		return b.loadResourceFromProject()
+		err = b.loadResourceFromProject()
+	} else {
+		err = b.loadResourceFromDesginatedDir()
+	}
+	if err != nil {
+		return err
+	}
+
+	err = b.loadPlatformResources()
+	if err != nil {
+		return err
 	}
-	return b.loadResourceFromDesginatedDir()
+
+	return b.loadBundles()
 }
 
 func (b *BuildContext) loadResourceFromDesginatedDir() error {
 	fmt.Printf("\n>> copying resources...\n")
-	command := fmt.Sprintf("cp -r * %s", b.workingDir)
-	out, err := ExecuteShell(b.ResourceDir, command)
+	followSymlink, err := b.followSymlink()
 	if err != nil {
-		return fmt.Errorf("fail to execute command : %s\n%s\n", err.Error(), out)
+		return err
 	}
-	if len(out) > 0 {
-		return fmt.Errorf("fail to copy resources\n%s\n", out)
+	copied, err := CopyDirectory(b.ResourceDir, b.workingDir, followSymlink, isReservedResourceDir)
+	if err != nil {
+		return fmt.Errorf("fail to copy resources : %s", err.Error())
+	}
+	fmt.Printf("total %d resource files copied...\n", len(copied))
+
+	if len(b.Profile) == 0 {
+		return nil
 	}
-	fmt.Printf("resources directory copied...\n")
+
+	fmt.Printf("\n>> applying profile %s...\n", b.Profile)
+	var merged []string
+	if b.project.Properties.Merge {
+		merged, err = b.mergeProfileProperties()
+		if err != nil {
+			return fmt.Errorf("fail to apply profile %s : %s", b.Profile, err.Error())
+		}
+	}
+	skip := func(relPath string) bool {
+		if relPath == PlatformDirName {
+			return true
+		}
+		for _, m := range merged {
+			if relPath == m {
+				return true
+			}
+		}
+		return false
+	}
+
+	copied, err = CopyDirectory(b.profileDir(), b.workingDir, followSymlink, skip)
+	if err != nil {
+		return fmt.Errorf("fail to apply profile %s : %s", b.Profile, err.Error())
+	}
+	fmt.Printf("total %d profile resource files overlaid, %d properties merged...\n", len(copied), len(merged))
 	return nil
 }
 
-var includeSuffixList = [...]string{"properties", "xml", "json", "yaml", "sh", "yml"}
-
 func (b *BuildContext) loadResourceFromProject() error {
-	resourceFileList, err := findResourceFromDirectory(b.ProjectBaseDir)
+	resourceList, err := b.findProjectResources()
 	if err != nil {
 		return err
 	}
 
-	for _, resourceFilePath := range resourceFileList {
-		targetFile := filepath.Join(b.workingDir, filepath.Base(resourceFilePath))
-		err = CopyFile(resourceFilePath, targetFile)
+	for _, resource := range resourceList {
+		targetFile := filepath.Join(b.workingDir, resource.Target)
+		err = EnsureDirectory(filepath.Dir(targetFile))
 		if err != nil {
-			return fmt.Errorf("fail to copy resource %s : %s", resourceFilePath, err.Error())
+			return fmt.Errorf("fail to prepare resource dir %s : %s", resource.Target, err.Error())
+		}
+		err = CopyFile(resource.Source, targetFile)
+		if err != nil {
+			return fmt.Errorf("fail to copy resource %s : %s", resource.Source, err.Error())
 		}
 		if strings.HasSuffix(targetFile, ".sh") {
 			os.Chmod(targetFile, 0755)
 		}
 	}
 
-	fmt.Printf("total %d resource files copied...\n", len(resourceFileList))
+	fmt.Printf("total %d resource files copied...\n", len(resourceList))
 	return nil
 }
 
-func findResourceFromDirectory(baseDir string) ([]string, error) {
+// ListResourceFiles 패키징될 리소스 파일 목록을 구한다
+func (b *BuildContext) ListResourceFiles() ([]ResourceFile, error) {
+	err := b.checkProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	var resourceList []ResourceFile
+	if len(b.ResourceDir) == 0 {
+		resourceList, err = b.findProjectResources()
+	} else {
+		resourceList, err = b.listDesignatedDirResources()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	platformList, err := b.listPlatformResources()
+	if err != nil {
+		return nil, err
+	}
+	return append(resourceList, platformList...), nil
+}
+
+func (b *BuildContext) listDesignatedDirResources() ([]ResourceFile, error) {
+	resourceList, err := listDirectoryFiles(b.ResourceDir, isReservedResourceDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.Profile) == 0 {
+		return resourceList, nil
+	}
+
+	profileList, err := listDirectoryFiles(b.profileDir(), func(relPath string) bool {
+		return relPath == PlatformDirName
+	})
+	if err != nil {
+		return nil, err
+	}
+	return overlayResourceFiles(resourceList, profileList), nil
+}
+
+// listDirectoryFiles dir 하위의 파일들을 dir 기준 상대경로로 구한다
+func listDirectoryFiles(dir string, skip func(relPath string) bool) ([]ResourceFile, error) {
+	resourceList := make([]ResourceFile, 0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if skip != nil && rel != "." && skip(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		resourceList = append(resourceList, ResourceFile{Source: path, Target: rel})
+		return nil
+	})
+	return resourceList, err
+}
+
+// findProjectResources .farignore 와 프로젝트 설정의 include/exclude 를 적용하여 프로젝트의 리소스 파일을 찾는다
+func (b *BuildContext) findProjectResources() ([]ResourceFile, error) {
+	filter, err := NewResourceFilter(b.ProjectBaseDir, b.project.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	fileList, err := findResourceFromDirectory(b.ProjectBaseDir, b.ProjectBaseDir, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveResourceTargets(b.ProjectBaseDir, fileList, b.project.Resource)
+}
+
+func findResourceFromDirectory(rootDir, baseDir string, filter *ResourceFilter) ([]string, error) {
 	resourceFileList := make([]string, 0)
 
 	files, err := os.ReadDir(baseDir)
@@ -272,8 +522,17 @@ func findResourceFromDirectory(baseDir string) ([]string, error) {
 			continue
 		}
 
+		path := filepath.Join(baseDir, file.Name())
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return resourceFileList, err
+		}
+
 		if file.IsDir() {
-			foundFileList, err := findResourceFromDirectory(filepath.Join(baseDir, file.Name()))
+			if filter.SkipDir(rel) {
+				continue
+			}
+			foundFileList, err := findResourceFromDirectory(rootDir, path, filter)
 			if err != nil {
 				return resourceFileList, err
 			}
@@ -281,11 +540,8 @@ func findResourceFromDirectory(baseDir string) ([]string, error) {
 			continue
 		}
 
-		for _, s := range includeSuffixList {
-			if strings.HasSuffix(file.Name(), s) {
-				resourceFileList = append(resourceFileList, filepath.Join(baseDir, file.Name()))
-				break
-			}
+		if filter.Accept(rel) {
+			resourceFileList = append(resourceFileList, path)
 		}
 	}
 	return resourceFileList, nil
@@ -293,59 +549,125 @@ func findResourceFromDirectory(baseDir string) ([]string, error) {
 
 // prepare binaries...
 func (b *BuildContext) prepareBinary() error {
+	if b.packageMode == packageModeScript {
+		fmt.Printf("\n>> %s package. skip compiling binaries\n", packageModeScript)
+		return nil
+	}
+
 	if len(b.ProcessList) == 0 {
 		return fmt.Errorf("not found target process list")
 	}
 
+	// 바이너리를 선택한 경우는 SelectBinaries 에서 검사한다
+	if !b.binarySelected {
+		err := checkDuplicateBinaries(b.ProcessList)
+		if err != nil {
+			return err
+		}
+	}
+
 	return b.prepareCmdRecordBinary()
 }
 
 func (b *BuildContext) prepareCmdRecordBinary() error {
 	// build process list
 	for _, cmdRecord := range b.ProcessList {
-		cmdBinName := cmdRecord.GetBinaryname()
-		fmt.Printf("\n>> compiling %s...\n", cmdBinName)
+		if b.scheduler != nil {
+			err := b.scheduler.Prepare(cmdRecord, b.workingDir)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := compileCmdRecord(cmdRecord, b.workingDir)
+		if err != nil {
+			return err
+		}
+	}
 
-		var compileError uint32 = 0
+	return nil
+}
 
-		// local 플랫폼을 먼저 빌드한다, 이후 에러가 없을 경우 추가 플랫폼을 빌드한다
+// compileCmdRecord 빌드 대상 플랫폼별로 cmdRecord 바이너리를 컴파일하여 workingDir/platform 하위에 생성한다
+func compileCmdRecord(cmdRecord CmdRecord, workingDir string) error {
+	cmdBinName := cmdRecord.GetBinaryname()
+	fmt.Printf("\n>> compiling %s...\n", cmdBinName)
 
-		CgoCCLink := ""
-		compileRequest := createCompileRequest(buildPlatformList.GetLocalPlatform(), cmdRecord, b.workingDir, CgoCCLink)
-		compileBinary(&compileError, compileRequest)
-		if compileError > 0 {
-			return fmt.Errorf("fail to prepare binary %s\n", cmdBinName)
-		}
+	var compileError uint32 = 0
+
+	// local 플랫폼을 먼저 빌드한다, 이후 에러가 없을 경우 추가 플랫폼을 빌드한다
+	// local 플랫폼이 빌드 대상이 아니면 컴파일 확인용으로만 빌드하고 결과는 버린다
+	err := compileLocalPlatform(cmdRecord, workingDir)
+	if err != nil {
+		return err
+	}
 
-		// 추가 플랫폼을 빌드한다
-		wg := sync.WaitGroup{}
-		additionalPlatforms := buildPlatformList.GetAdditionalPlatforms()
-		wg.Add(len(additionalPlatforms))
-		for _, platform := range additionalPlatforms {
-			nextCompileRequest := createCompileRequest(platform, cmdRecord, b.workingDir, platform.CC)
-			go func() {
-				defer wg.Done()
-				compileBinary(&compileError, nextCompileRequest)
-			}()
+	// 추가 플랫폼을 빌드한다
+	wg := sync.WaitGroup{}
+	additionalPlatforms := buildPlatformList.GetAdditionalPlatforms()
+	wg.Add(len(additionalPlatforms))
+	for _, platform := range additionalPlatforms {
+		nextCompileRequest := createCompileRequest(platform, cmdRecord, workingDir, platform.CC)
+		go func() {
+			defer wg.Done()
+			compileBinary(&compileError, nextCompileRequest)
+		}()
+	}
+	wg.Wait()
+
+	if compileError > 0 {
+		return fmt.Errorf("fail to prepare binary %s\n", cmdBinName)
+	}
+
+	return nil
+}
+
+// compileLocalPlatform 로컬 플랫폼 바이너리를 빌드한다
+// 로컬 플랫폼이 빌드 대상이 아니면 임시 디렉토리에 빌드하여 컴파일 오류만 확인하며, -skip-local 옵션이면 건너뛴다
+func compileLocalPlatform(cmdRecord CmdRecord, workingDir string) error {
+	cmdBinName := cmdRecord.GetBinaryname()
+	local := buildPlatformList.GetLocalPlatform()
+	if !buildPlatformList.IsLocalTarget() {
+		if skipLocalBuild {
+			fmt.Printf("skip local platform %s compile check\n", local.getPlatformDirectory())
+			return nil
 		}
-		wg.Wait()
 
-		if compileError > 0 {
-			return fmt.Errorf("fail to prepare binary %s\n", cmdBinName)
+		fmt.Printf("local platform %s is not a target. compile check only\n", local.getPlatformDirectory())
+		checkDir, err := os.MkdirTemp("", "gofar_check")
+		if err != nil {
+			return fmt.Errorf("fail to create tmp dir : %s", err.Error())
 		}
+		defer func() {
+			_ = os.RemoveAll(checkDir)
+		}()
+		workingDir = checkDir
 	}
 
+	var compileError uint32 = 0
+	CgoCCLink := ""
+	compileRequest := createCompileRequest(local, cmdRecord, workingDir, CgoCCLink)
+	compileBinary(&compileError, compileRequest)
+	if compileError > 0 {
+		return fmt.Errorf("fail to prepare binary %s\n", cmdBinName)
+	}
 	return nil
 }
 
 func createCompileRequest(platform PlatformItem, cmdRecord CmdRecord, workingDir, cgoLink string) BinCompileRequest {
 	request := BinCompileRequest{}
 	request.TargetDir = filepath.Join(workingDir, PlatformDirName, platform.getPlatformDirectory())
-	request.BinName = cmdRecord.GetBinaryname()
+	request.BinName = platform.executableName(cmdRecord.GetBinaryname())
 	request.BinSourcePath = cmdRecord.Path
 	request.Os = platform.Os
 	request.Arch = platform.Arch
 	request.BuildCGOLink = cgoLink
+	request.Env = platform.buildEnv()
+	request.Tags = platform.Tags
+	request.Ldflags = platform.Ldflags
+	request.Gcflags = platform.Gcflags
+	request.Buildmode = platform.Buildmode
 	return request
 }
 
@@ -360,6 +682,11 @@ type BinCompileRequest struct {
 	Os            string
 	Arch          string
 	BuildCGOLink  string
+	Env           map[string]string
+	Tags          []string
+	Ldflags       string
+	Gcflags       string
+	Buildmode     string
 }
 
 // compileBinary 바이너리를 컴파일한다
@@ -374,18 +701,7 @@ func compileBinary(compileError *uint32, request BinCompileRequest) {
 	}
 
 	targetBin := filepath.Join(request.TargetDir, request.BinName)
-	command := fmt.Sprintf("go build -o %s", targetBin)
-
-	command = fmt.Sprintf("GOOS=%s GOARCH=%s go build -o %s", request.Os, request.Arch, targetBin)
-	if cgoEnable {
-		if len(request.BuildCGOLink) > 0 {
-			command = fmt.Sprintf("CC=%s %s", request.BuildCGOLink, command)
-		}
-		command = fmt.Sprintf("CGO_ENABLED=1 %s", command)
-		if stripEnable {
-			command = fmt.Sprintf("%s -ldflags='-s -w'", command)
-		}
-	}
+	command := buildCompileCommand(request, targetBin)
 
 	fmt.Printf("%s\n", command)
 	out, err := ExecuteShell(request.BinSourcePath, command)
@@ -401,11 +717,79 @@ func compileBinary(compileError *uint32, request BinCompileRequest) {
 		return
 	}
 
+	err = verifyCompiledBinary(request, targetBin)
+	if err != nil {
+		fmt.Printf("invalid binary %s : %s : %s\n", filepath.Base(request.TargetDir), request.BinName, err.Error())
+		atomic.AddUint32(compileError, 1)
+		return
+	}
+
 	_ = os.Chmod(targetBin, 0755)
 }
 
+// verifyCompiledBinary 컴파일된 바이너리가 대상 플랫폼용 실행 파일인지 확인한다
+// c-archive, c-shared, plugin 등 실행 파일을 만들지 않는 buildmode 는 확인하지 않는다
+func verifyCompiledBinary(request BinCompileRequest, targetBin string) error {
+	switch request.Buildmode {
+	case "", "default", "exe", "pie":
+		return verifyBinaryPlatform(targetBin, PlatformItem{Os: request.Os, Arch: request.Arch})
+	}
+	return nil
+}
+
+// buildCompileCommand 플랫폼별 환경변수와 빌드 옵션을 적용한 go build 쉘 커맨드를 생성한다
+func buildCompileCommand(request BinCompileRequest, targetBin string) string {
+	envList := make([]string, 0)
+	cgo := cgoEnabled(request.Env, cgoEnable)
+	if cgo {
+		// 플랫폼 env 에 CGO_ENABLED 가 지정된 경우는 아래에서 env 로 적용된다
+		if _, ok := request.Env["CGO_ENABLED"]; !ok {
+			envList = append(envList, "CGO_ENABLED=1")
+		}
+		if len(request.BuildCGOLink) > 0 {
+			envList = append(envList, fmt.Sprintf("CC=%s", request.BuildCGOLink))
+		}
+	}
+	envList = append(envList, fmt.Sprintf("GOOS=%s", request.Os), fmt.Sprintf("GOARCH=%s", request.Arch))
+
+	keys := make([]string, 0, len(request.Env))
+	for k := range request.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		envList = append(envList, fmt.Sprintf("%s=%s", k, shellQuote(request.Env[k])))
+	}
+
+	command := fmt.Sprintf("%s go build -o %s", strings.Join(envList, " "), shellQuote(targetBin))
+	if len(request.Tags) > 0 {
+		command = fmt.Sprintf("%s -tags=%s", command, shellQuote(strings.Join(request.Tags, ",")))
+	}
+
+	ldflags := request.Ldflags
+	if cgo && stripEnable {
+		ldflags = strings.TrimSpace("-s -w " + ldflags)
+	}
+	if len(ldflags) > 0 {
+		command = fmt.Sprintf("%s -ldflags=%s", command, shellQuote(ldflags))
+	}
+	if len(request.Gcflags) > 0 {
+		command = fmt.Sprintf("%s -gcflags=%s", command, shellQuote(request.Gcflags))
+	}
+	if len(request.Buildmode) > 0 {
+		command = fmt.Sprintf("%s -buildmode=%s", command, shellQuote(request.Buildmode))
+	}
+	return command
+}
+
 func NewBuildContext(procName string) (*BuildContext, error) {
 	loadPlatform()
+	if len(platformFilter) > 0 {
+		err := buildPlatformList.SelectPlatforms(platformFilter)
+		if err != nil {
+			return nil, fmt.Errorf("fail to build context. %s", err.Error())
+		}
+	}
 
 	ctx := &BuildContext{}
 	ctx.GitSupport = false
@@ -417,18 +801,122 @@ func NewBuildContext(procName string) (*BuildContext, error) {
 		return nil, fmt.Errorf("fail to build context. %s", err.Error())
 	}
 
+	ctx.project, err = loadProjectConfig(ctx.ProjectBaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("fail to build context. %s", err.Error())
+	}
+
+	err = determineWorkspace(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fail to build context. %s", err.Error())
+	}
+	ctx.project.applyProcessEntry(procName)
+
 	determineResourceDir(ctx)
-	determineCmdList(ctx)
+	err = determineCmdList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fail to build context. %s", err.Error())
+	}
+
+	err = ctx.SelectBinaries(ctx.project.Processes[procName].Binaries)
+	if err != nil {
+		return nil, fmt.Errorf("fail to build context. %s", err.Error())
+	}
+
+	err = determinePackageMode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fail to build context. %s", err.Error())
+	}
 
 	return ctx, nil
 }
 
+// SelectBinaries 탐색된 main 패키지 중 binNames 에 해당하는 바이너리만 far 에 포함한다
+// binNames 가 비어있으면 모든 바이너리를 포함한다. 다시 호출하면 이전 선택을 대체한다
+func (b *BuildContext) SelectBinaries(binNames []string) error {
+	if len(binNames) == 0 {
+		return nil
+	}
+
+	// 이름이 같은 main 패키지가 있더라도 선택되지 않으면 빌드할 수 있도록 선택된 이름에 대해서만 중복을 검사한다
+	recordMap := make(map[string][]CmdRecord)
+	available := make([]string, 0, len(b.mainPackages))
+	for _, record := range b.mainPackages {
+		name := record.GetBinaryname()
+		if _, ok := recordMap[name]; !ok {
+			available = append(available, name)
+		}
+		recordMap[name] = append(recordMap[name], record)
+	}
+
+	selected := make([]CmdRecord, 0, len(binNames))
+	selectedMap := make(map[string]struct{})
+	for _, name := range binNames {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		records, ok := recordMap[name]
+		if !ok {
+			return fmt.Errorf("binary %s not found (available : %s)", name, strings.Join(available, ","))
+		}
+		err := checkDuplicateBinaries(records)
+		if err != nil {
+			return err
+		}
+		if _, ok := selectedMap[name]; ok {
+			return fmt.Errorf("binary %s is selected more than once", name)
+		}
+		selected = append(selected, records[0])
+		selectedMap[name] = struct{}{}
+	}
+
+	b.ProcessList = selected
+	b.binarySelected = true
+	return nil
+}
+
+// checkDuplicateBinaries 바이너리 이름이 같은 main 패키지는 같은 far 에 포함할 수 없다
+func checkDuplicateBinaries(cmdList []CmdRecord) error {
+	nameMap := make(map[string]string)
+	for _, record := range cmdList {
+		name := record.GetBinaryname()
+		if prev, ok := nameMap[name]; ok {
+			return fmt.Errorf("duplicate binary name %s : %s, %s", name, prev, record.Path)
+		}
+		nameMap[name] = record.Path
+	}
+	return nil
+}
+
+// determinePackageMode 프로젝트 설정의 process.package 값 또는 main 패키지 존재 여부로 패키징 모드를 결정한다
+// script 모드에서는 go 바이너리를 빌드하지 않고 스크립트와 리소스만 패키징한다
+func determinePackageMode(ctx *BuildContext) error {
+	switch ctx.project.Process.Package {
+	case packageModeScript:
+		ctx.packageMode = packageModeScript
+		ctx.ProcessList = make([]CmdRecord, 0)
+	case packageModeBinary:
+		if len(ctx.ProcessList) == 0 {
+			return fmt.Errorf("not found main package for binary package")
+		}
+		ctx.packageMode = packageModeBinary
+	case "":
+		ctx.packageMode = packageModeBinary
+		if len(ctx.ProcessList) == 0 {
+			ctx.packageMode = packageModeScript
+		}
+	default:
+		return fmt.Errorf("invalid process package %s (%s or %s)", ctx.project.Process.Package, packageModeBinary, packageModeScript)
+	}
+	return nil
+}
+
 func determineResourceDir(ctx *BuildContext) {
 	resourceDir := filepath.Join(ctx.ProjectBaseDir, resourceDirname)
 
 	err := CheckDirExist(resourceDir)
 	if err != nil {
-		fmt.Errorf("ensure resource dir : %s", err.Error())
 		return
 	}
 
@@ -443,6 +931,7 @@ func determineProjectBaseDir(ctx *BuildContext) error {
 	foundBaseDir, err := FindGitConfig(currentWd)
 	if err == nil {
 		ctx.ProjectBaseDir = foundBaseDir
+		ctx.gitBaseDir = foundBaseDir
 		ctx.GitSupport = true
 		return nil
 	}
@@ -470,21 +959,24 @@ func determineProjectBaseDir(ctx *BuildContext) error {
 	return nil
 }
 
-// find project base dir
-func determineCmdList(ctx *BuildContext) {
-	ctx.ProcessList = make([]CmdRecord, 0)
-
-	foundDir, err := FindDirectory(ctx.ProjectBaseDir, cmdDirname)
+// determineCmdList 프로젝트에서 빌드할 main 패키지 목록을 구한다
+func determineCmdList(ctx *BuildContext) error {
+	cmdList, err := discoverMainPackages(ctx.ProjectBaseDir)
 	if err != nil {
-		record := CmdRecord{}
-		record.Path = ctx.ProjectBaseDir
-		ctx.ProcessList = append(ctx.ProcessList, record)
-		return
+		return err
 	}
 
-	for _, cmd := range FindSubDirectories(foundDir) {
-		record := CmdRecord{}
-		record.Path = cmd
-		ctx.ProcessList = append(ctx.ProcessList, record)
+	ctx.ProcessList = cmdList
+	ctx.mainPackages = cmdList
+	if len(cmdList) == 0 {
+		fmt.Printf("main package not found in %s\n", ctx.ProjectBaseDir)
+		return nil
+	}
+
+	fmt.Printf("found %d main packages\n", len(cmdList))
+	for _, record := range cmdList {
+		rel, _ := filepath.Rel(ctx.ProjectBaseDir, record.Path)
+		fmt.Printf("  %s (./%s)\n", record.GetBinaryname(), filepath.ToSlash(rel))
 	}
+	return nil
 }
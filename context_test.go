@@ -0,0 +1,115 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 4:10
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPackageModeDeployment(t *testing.T) {
+	hello := CmdRecord{Path: "/project/cmd/hello"}
+
+	testCases := []struct {
+		name           string
+		process        ProcessConfig
+		processList    []CmdRecord
+		binarySelected bool
+		procType       string
+		expectMode     string
+		expectErr      bool
+		expectBinaries bool
+		expectProcType bool
+	}{
+		{name: "binary", processList: []CmdRecord{hello}, binarySelected: true, procType: procTypeGeneral,
+			expectMode: packageModeBinary, expectBinaries: true, expectProcType: true},
+		{name: "no main package", procType: procTypeGeneral,
+			expectMode: packageModeScript},
+		{name: "explicit script", process: ProcessConfig{Package: packageModeScript}, processList: []CmdRecord{hello},
+			binarySelected: true, procType: procTypeGeneral, expectMode: packageModeScript},
+		{name: "script with ui", process: ProcessConfig{Package: packageModeScript}, procType: procTypeUI,
+			expectMode: packageModeScript, expectProcType: true},
+		{name: "script with explicit type", process: ProcessConfig{Package: packageModeScript, Type: procTypeGeneral},
+			procType: procTypeGeneral, expectMode: packageModeScript, expectProcType: true},
+		{name: "binary without main package", process: ProcessConfig{Package: packageModeBinary}, expectErr: true},
+		{name: "invalid package", process: ProcessConfig{Package: "docker"}, processList: []CmdRecord{hello}, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &BuildContext{ExposeProcessName: "hello", ProcessList: tc.processList, binarySelected: tc.binarySelected}
+			ctx.project.Process = tc.process
+			err := determinePackageMode(ctx)
+			if tc.expectErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectMode, ctx.packageMode)
+
+			ctx.procType = tc.procType
+			ctx.workingDir = t.TempDir()
+			ctx.buildInfo = map[string]interface{}{"time": "2026-10-19 16:10:00 KST", "user": "dave"}
+			assert.Nil(t, ctx.createDeployment())
+
+			m, err := readDeployment(ctx.workingDir)
+			assert.Nil(t, err)
+			_, ok := m["binaries"]
+			assert.Equal(t, tc.expectBinaries, ok)
+			procType, ok := m["process_type"]
+			assert.Equal(t, tc.expectProcType, ok)
+			if ok {
+				assert.Equal(t, tc.procType, procType)
+			}
+			if tc.expectMode == packageModeScript {
+				assert.Equal(t, packageModeScript, m["package"])
+			} else {
+				assert.Nil(t, m["package"])
+			}
+		})
+	}
+}
+
+func TestProcessEntryOverride(t *testing.T) {
+	hello := CmdRecord{Path: "/project/cmd/hello"}
+
+	ctx := &BuildContext{ExposeProcessName: "hello", ProcessList: []CmdRecord{hello}, binarySelected: true}
+	ctx.project.Process = ProcessConfig{Type: procTypeGeneral, Package: packageModeBinary}
+	ctx.project.Processes = map[string]ProcessEntry{
+		"hello": {Type: procTypeUI, Package: packageModeScript},
+	}
+
+	// 프로세스별 설정이 process 설정보다 우선한다
+	ctx.project.applyProcessEntry("hello")
+	assert.Nil(t, determinePackageMode(ctx))
+	assert.Equal(t, packageModeScript, ctx.packageMode)
+
+	ctx.workingDir = t.TempDir()
+	procType, err := ctx.resolveProcType()
+	assert.Nil(t, err)
+	assert.Equal(t, procTypeUI, procType)
+
+	// 설정이 없는 프로세스는 process 설정을 그대로 사용한다
+	other := ProjectConfig{Process: ProcessConfig{Type: procTypeGeneral}, Processes: ctx.project.Processes}
+	other.applyProcessEntry("order")
+	assert.Equal(t, procTypeGeneral, other.Process.Type)
+	assert.Empty(t, other.Process.Package)
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오전 11:30
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GoPackage go list -json 결과 중 사용하는 항목
+type GoPackage struct {
+	Dir        string
+	ImportPath string
+	Name       string
+	GoFiles    []string
+	Error      *GoPackageError
+}
+
+type GoPackageError struct {
+	Err string
+}
+
+// listGoPackages go list -json ./... 으로 dir 하위의 패키지 목록을 구한다
+func listGoPackages(dir string) ([]GoPackage, error) {
+	cmd := exec.Command("go", "list", "-e", "-json", "./...")
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("go list error : %s\n%s", err.Error(), stderr.String())
+	}
+
+	pkgList := make([]GoPackage, 0)
+	decoder := json.NewDecoder(&stdout)
+	for {
+		var pkg GoPackage
+		err = decoder.Decode(&pkg)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid go list output : %s", err.Error())
+		}
+		// 패턴 자체를 처리할 수 없는 경우(모듈이 아닌 디렉토리 등)
+		if len(pkg.Dir) == 0 && pkg.Error != nil {
+			return nil, fmt.Errorf("go list error : %s", pkg.Error.Err)
+		}
+		pkgList = append(pkgList, pkg)
+	}
+	return pkgList, nil
+}
+
+// scanMainPackages go list 를 사용할 수 없는 경우(GOPATH 모드 등) go/parser 로 main 패키지를 찾는다
+// vendor, testdata, '.' 또는 '_' 로 시작하는 디렉토리와 별도의 go.mod 가 있는 하위 모듈은 제외한다
+func scanMainPackages(baseDir string) ([]GoPackage, error) {
+	pkgList := make([]GoPackage, 0)
+	err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != baseDir {
+			name := info.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if CheckFileExist(filepath.Join(path, "go.mod")) == nil {
+				return filepath.SkipDir
+			}
+		}
+		if HasMainPackage(path) {
+			pkgList = append(pkgList, GoPackage{Dir: path, Name: "main", GoFiles: goSourceFiles(path)})
+		}
+		return nil
+	})
+	return pkgList, err
+}
+
+func goSourceFiles(dir string) []string {
+	list := make([]string, 0)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return list
+	}
+	for _, file := range files {
+		name := file.Name()
+		if !file.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// findMainSourceFile main 함수가 정의된 소스 파일을 찾는다
+func findMainSourceFile(dir string, goFiles []string) string {
+	fset := token.NewFileSet()
+	for _, name := range goFiles {
+		path := filepath.Join(dir, name)
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// discoverMainPackages 프로젝트에서 바이너리로 빌드할 main 패키지들을 찾는다
+// 경로에 cmd 디렉토리를 포함하는 main 패키지(cmd/a, cmd/a/b, service/cmd/x)가 있으면 해당 패키지들을,
+// 없으면 프로젝트 base 디렉토리의 main 패키지를 대상으로 한다
+func discoverMainPackages(baseDir string) ([]CmdRecord, error) {
+	pkgList, err := listGoPackages(baseDir)
+	if err != nil {
+		fmt.Printf("%s\nfallback to scan source files\n", strings.TrimSpace(err.Error()))
+		pkgList, err = scanMainPackages(baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("fail to scan main packages : %s", err.Error())
+		}
+	}
+
+	cmdList := make([]CmdRecord, 0)
+	otherList := make([]string, 0)
+	var rootRecord *CmdRecord
+	for _, pkg := range pkgList {
+		if pkg.Name != "main" {
+			continue
+		}
+
+		rel, err := filepath.Rel(baseDir, pkg.Dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		record := CmdRecord{Path: pkg.Dir, ImportPath: pkg.ImportPath, MainFile: findMainSourceFile(pkg.Dir, pkg.GoFiles)}
+		if rel == "." {
+			rootRecord = &record
+			continue
+		}
+
+		underCmd := false
+		for _, element := range strings.Split(filepath.ToSlash(rel), "/") {
+			if element == cmdDirname {
+				underCmd = true
+				break
+			}
+		}
+		if underCmd {
+			cmdList = append(cmdList, record)
+		} else {
+			otherList = append(otherList, rel)
+		}
+	}
+
+	sort.Strings(otherList)
+	if len(cmdList) == 0 {
+		if rootRecord != nil {
+			cmdList = append(cmdList, *rootRecord)
+		} else if len(otherList) > 0 {
+			// main 패키지가 있는데 빌드 대상이 없으면 script 패키지로 처리하지 않고 실패한다
+			return nil, fmt.Errorf("main packages found outside of %s directory : %s. move them under %s/ or the project base directory",
+				cmdDirname, strings.Join(otherList, ","), cmdDirname)
+		}
+	}
+	for _, rel := range otherList {
+		fmt.Printf("main package %s is not under %s directory. skip\n", rel, cmdDirname)
+	}
+
+	sort.Slice(cmdList, func(i, j int) bool {
+		return cmdList[i].Path < cmdList[j].Path
+	})
+
+	return cmdList, nil
+}
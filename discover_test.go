@@ -0,0 +1,154 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 12:10
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestSources(t *testing.T, baseDir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(baseDir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestDiscoverMainPackages(t *testing.T) {
+	mainSource := "package main\n\nfunc main() {}\n"
+	baseDir := t.TempDir()
+	writeTestSources(t, baseDir, map[string]string{
+		"go.mod":                   "module example.com/hello\n\ngo 1.16\n",
+		"cmd/server/run.go":        mainSource,
+		"cmd/tools/migrate/app.go": mainSource,
+		"cmd/README.go":            "package cmd\n",
+		"internal/lib/lib.go":      "package lib\n",
+		"testdata/sample/main.go":  mainSource,
+		"vendor/x/main.go":         mainSource,
+	})
+
+	cmdList, err := discoverMainPackages(baseDir)
+	assert.Nil(t, err)
+	assert.Len(t, cmdList, 2)
+	assert.Equal(t, "server", cmdList[0].GetBinaryname())
+	assert.Equal(t, filepath.Join(baseDir, "cmd/server/run.go"), cmdList[0].GetMainSourcePath())
+	assert.Equal(t, "example.com/hello/cmd/server", cmdList[0].ImportPath)
+	assert.Equal(t, "migrate", cmdList[1].GetBinaryname())
+
+	// go.mod 가 없는 경우 소스 파일을 직접 검사한다
+	assert.Nil(t, os.Remove(filepath.Join(baseDir, "go.mod")))
+	cmdList, err = discoverMainPackages(baseDir)
+	assert.Nil(t, err)
+	assert.Len(t, cmdList, 2)
+}
+
+func TestDiscoverRootMainPackage(t *testing.T) {
+	baseDir := t.TempDir()
+	writeTestSources(t, baseDir, map[string]string{
+		"go.mod":   "module example.com/hello\n\ngo 1.16\n",
+		"main.go":  "package main\n\nfunc main() {}\n",
+		"lib/a.go": "package lib\n",
+	})
+
+	cmdList, err := discoverMainPackages(baseDir)
+	assert.Nil(t, err)
+	assert.Len(t, cmdList, 1)
+	assert.Equal(t, baseDir, cmdList[0].Path)
+}
+
+func TestDiscoverMainPackageOutsideCmd(t *testing.T) {
+	mainSource := "package main\n\nfunc main() {}\n"
+	baseDir := t.TempDir()
+	writeTestSources(t, baseDir, map[string]string{
+		"go.mod":            "module example.com/hello\n\ngo 1.16\n",
+		"server/main.go":    mainSource,
+		"tools/gen/main.go": mainSource,
+		"internal/lib/a.go": "package lib\n",
+	})
+
+	_, err := discoverMainPackages(baseDir)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "server,"+filepath.Join("tools", "gen"))
+
+	// cmd 하위에 main 패키지가 있으면 그 외의 main 패키지는 빌드하지 않는다
+	writeTestSources(t, baseDir, map[string]string{"cmd/api/main.go": mainSource})
+	cmdList, err := discoverMainPackages(baseDir)
+	assert.Nil(t, err)
+	assert.Len(t, cmdList, 1)
+	assert.Equal(t, "api", cmdList[0].GetBinaryname())
+}
+
+func TestDiscoverDuplicateBinaryName(t *testing.T) {
+	mainSource := "package main\n\nfunc main() {}\n"
+	baseDir := t.TempDir()
+	writeTestSources(t, baseDir, map[string]string{
+		"go.mod":               "module example.com/hello\n\ngo 1.16\n",
+		"a/cmd/api/main.go":    mainSource,
+		"b/cmd/api/main.go":    mainSource,
+		"b/cmd/worker/main.go": mainSource,
+	})
+
+	// 탐색 단계에서는 중복을 허용하고, far 에 포함되는 바이너리에 대해서만 검사한다
+	cmdList, err := discoverMainPackages(baseDir)
+	assert.Nil(t, err)
+	assert.Len(t, cmdList, 3)
+
+	err = checkDuplicateBinaries(cmdList)
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Sprintf("duplicate binary name api : %s, %s",
+		filepath.Join(baseDir, "a", "cmd", "api"), filepath.Join(baseDir, "b", "cmd", "api")), err.Error())
+	assert.Nil(t, checkDuplicateBinaries(cmdList[1:]))
+}
+
+func TestSelectBinaries(t *testing.T) {
+	ctx := &BuildContext{}
+	ctx.mainPackages = []CmdRecord{{Path: "/p/cmd/api"}, {Path: "/p/cmd/worker"}, {Path: "/p/cmd/migrate"}}
+	ctx.ProcessList = ctx.mainPackages
+
+	assert.Nil(t, ctx.SelectBinaries(nil))
+	assert.Equal(t, 3, len(ctx.ProcessList))
+
+	assert.Nil(t, ctx.SelectBinaries([]string{"migrate", " api"}))
+	assert.Equal(t, []CmdRecord{{Path: "/p/cmd/migrate"}, {Path: "/p/cmd/api"}}, ctx.ProcessList)
+
+	// 이전 선택과 무관하게 전체 main 패키지에서 다시 선택한다
+	assert.Nil(t, ctx.SelectBinaries([]string{"worker"}))
+	assert.Equal(t, []CmdRecord{{Path: "/p/cmd/worker"}}, ctx.ProcessList)
+
+	assert.NotNil(t, ctx.SelectBinaries([]string{"unknown"}))
+
+	err := ctx.SelectBinaries([]string{"api", "worker", "api"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "binary api is selected more than once", err.Error())
+
+	// 이름이 중복된 바이너리는 선택된 경우에만 실패한다
+	ctx.mainPackages = append(ctx.mainPackages, CmdRecord{Path: "/p/tools/cmd/api"})
+	assert.Nil(t, ctx.SelectBinaries([]string{"worker"}))
+	assert.Equal(t, []CmdRecord{{Path: "/p/cmd/worker"}}, ctx.ProcessList)
+
+	err = ctx.SelectBinaries([]string{"worker", "api"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "duplicate binary name api : /p/cmd/api, /p/tools/cmd/api", err.Error())
+}
@@ -0,0 +1,306 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 4:05
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	farIgnoreFilename = ".farignore"
+)
+
+// defaultResourceSuffixList 프로젝트에서 리소스로 수집하는 기본 확장자 목록
+var defaultResourceSuffixList = []string{"properties", "xml", "json", "yaml", "sh", "yml"}
+
+// builtinIgnorePatterns 프로젝트에서 리소스를 수집할 때 기본으로 제외하는 디렉토리
+var builtinIgnorePatterns = []string{"vendor/", "testdata/", "node_modules/"}
+
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// IgnoreMatcher gitignore 형태의 패턴 목록으로 경로를 매칭한다
+// 마지막으로 매칭된 패턴이 결과를 결정하며 '!' 로 시작하는 패턴은 매칭을 취소한다
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+func NewIgnoreMatcher(patterns ...string) (*IgnoreMatcher, error) {
+	m := &IgnoreMatcher{rules: make([]ignoreRule, 0)}
+	err := m.AddPatterns(patterns...)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AddPatterns 패턴들을 추가한다. 빈 줄과 '#' 으로 시작하는 줄은 무시한다
+func (m *IgnoreMatcher) AddPatterns(patterns ...string) error {
+	for _, pattern := range patterns {
+		rule, ok, err := parseIgnorePattern(pattern)
+		if err != nil {
+			return err
+		}
+		if ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+	return nil
+}
+
+// AddFile gitignore 형식의 파일을 읽어 패턴들을 추가한다. 파일이 없으면 무시한다
+func (m *IgnoreMatcher) AddFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("fail to read %s : %s", path, err.Error())
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		err = m.AddPatterns(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d : %s", path, line, err.Error())
+		}
+	}
+	return scanner.Err()
+}
+
+func (m *IgnoreMatcher) Empty() bool {
+	return len(m.rules) == 0
+}
+
+// Match relPath 는 기준 디렉토리로부터의 상대경로이다
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(relPath)
+	matched := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(relPath) {
+			matched = !rule.negate
+		}
+	}
+	return matched
+}
+
+func parseIgnorePattern(pattern string) (ignoreRule, bool, error) {
+	rule := ignoreRule{pattern: pattern}
+
+	p := strings.TrimRight(pattern, " \t\r")
+	if len(p) == 0 || p[0] == '#' {
+		return rule, false, nil
+	}
+
+	if p[0] == '!' {
+		rule.negate = true
+		p = p[1:]
+	} else if strings.HasPrefix(p, "\\!") || strings.HasPrefix(p, "\\#") {
+		p = p[1:]
+	}
+
+	if strings.HasSuffix(p, "/") {
+		rule.dirOnly = true
+		p = strings.TrimRight(p, "/")
+	}
+	if len(p) == 0 {
+		return rule, false, nil
+	}
+
+	// '/' 가 포함된 패턴은 기준 디렉토리에 고정되고, 그렇지 않으면 모든 깊이에서 매칭한다
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	expr := globToRegexp(p)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(?:.*/)?" + expr + "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return rule, false, fmt.Errorf("invalid pattern %s : %s", pattern, err.Error())
+	}
+	rule.re = re
+	return rule, true, nil
+}
+
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					// "**/" 는 0개 이상의 디렉토리
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+				continue
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString("\\[")
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// SuffixMatcher 파일의 확장자 또는 glob 패턴으로 리소스 파일 여부를 판단한다
+// "sql", ".sql" 처럼 glob 문자('*', '?', '[')와 '/' 가 없는 항목은 확장자로,
+// 그렇지 않은 항목("*.pem", "migrations/*.sql")은 gitignore 형식의 패턴으로 취급한다
+type SuffixMatcher struct {
+	suffixList []string
+	globs      *IgnoreMatcher
+}
+
+func NewSuffixMatcher(entries ...string) (*SuffixMatcher, error) {
+	m := &SuffixMatcher{suffixList: make([]string, 0)}
+	m.globs, _ = NewIgnoreMatcher()
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		if strings.ContainsAny(entry, "*?[/") {
+			err := m.globs.AddPatterns(entry)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		m.suffixList = append(m.suffixList, "."+strings.TrimPrefix(entry, "."))
+	}
+	return m, nil
+}
+
+// Match relPath 파일이 확장자 또는 패턴에 매칭되는지 여부
+// publish, fresh 와 같이 확장자가 아닌 문자열로 끝나는 파일은 매칭되지 않는다
+func (m *SuffixMatcher) Match(relPath string) bool {
+	name := filepath.Base(relPath)
+	for _, suffix := range m.suffixList {
+		if len(name) > len(suffix) && strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return m.globs.Match(relPath, false)
+}
+
+// ResourceFilter 프로젝트 디렉토리에서 리소스 수집 대상을 결정한다
+type ResourceFilter struct {
+	suffix  *SuffixMatcher
+	ignore  *IgnoreMatcher
+	include *IgnoreMatcher
+}
+
+// NewResourceFilter 리소스 확장자 목록, 기본 제외 디렉토리, .farignore, 프로젝트 설정의 include/exclude 로 필터를 구성한다
+func NewResourceFilter(baseDir string, config ResourceConfig) (*ResourceFilter, error) {
+	suffixEntries := defaultResourceSuffixList
+	if len(config.Suffix) > 0 {
+		suffixEntries = config.Suffix
+	}
+	suffixEntries = append(append([]string{}, suffixEntries...), config.ExtraSuffix...)
+	suffix, err := NewSuffixMatcher(suffixEntries...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource suffix : %s", err.Error())
+	}
+
+	ignore, err := NewIgnoreMatcher(builtinIgnorePatterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ignore.AddFile(filepath.Join(baseDir, farIgnoreFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	err = ignore.AddPatterns(config.Exclude...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource exclude : %s", err.Error())
+	}
+
+	include, err := NewIgnoreMatcher(config.Include...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource include : %s", err.Error())
+	}
+
+	return &ResourceFilter{suffix: suffix, ignore: ignore, include: include}, nil
+}
+
+// SkipDir relDir 하위를 탐색하지 않을지 여부
+func (f *ResourceFilter) SkipDir(relDir string) bool {
+	return f.ignore.Match(relDir, true)
+}
+
+// Accept relPath 파일을 리소스로 수집할지 여부
+// 템플릿 파일(application.properties.tmpl)은 .tmpl 을 제외한 이름으로 확장자를 판단한다
+func (f *ResourceFilter) Accept(relPath string) bool {
+	if !f.suffix.Match(relPath) && !f.suffix.Match(strings.TrimSuffix(relPath, templateSuffix)) {
+		return false
+	}
+	if f.ignore.Match(relPath, false) {
+		return false
+	}
+	if f.include.Empty() {
+		return true
+	}
+	return f.include.Match(relPath, false)
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 4:30
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	m, err := NewIgnoreMatcher("# comment", "*.log", "/build/", "conf/**/*.sample.yaml", "!keep.log", "idea/")
+	assert.Nil(t, err)
+
+	assert.True(t, m.Match("app.log", false))
+	assert.True(t, m.Match("a/b/app.log", false))
+	assert.False(t, m.Match("a/b/keep.log", false))
+	assert.True(t, m.Match("build", true))
+	assert.False(t, m.Match("src/build", true))
+	assert.False(t, m.Match("build", false))
+	assert.True(t, m.Match("conf/x.sample.yaml", false))
+	assert.True(t, m.Match("conf/a/b/x.sample.yaml", false))
+	assert.False(t, m.Match("other/x.sample.yaml", false))
+	assert.True(t, m.Match("sub/idea", true))
+}
+
+func TestFindResourceFromDirectory(t *testing.T) {
+	baseDir := t.TempDir()
+	files := []string{
+		"application.properties",
+		"vendor/lib/config.yaml",
+		"testdata/sample.json",
+		"tool/node_modules/pkg/package.json",
+		"conf/app.yaml",
+		"conf/local.yaml",
+		"cmd/hello/hello.go",
+	}
+	for _, f := range files {
+		path := filepath.Join(baseDir, f)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, os.WriteFile(path, []byte{}, 0644))
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(baseDir, farIgnoreFilename), []byte("conf/local.yaml\n"), 0644))
+
+	filter, err := NewResourceFilter(baseDir, ResourceConfig{})
+	assert.Nil(t, err)
+	found, err := findResourceFromDirectory(baseDir, baseDir, filter)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(baseDir, "application.properties"),
+		filepath.Join(baseDir, "conf/app.yaml"),
+	}, found)
+
+	filter, err = NewResourceFilter(baseDir, ResourceConfig{Include: []string{"conf/**"}})
+	assert.Nil(t, err)
+	found, err = findResourceFromDirectory(baseDir, baseDir, filter)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{filepath.Join(baseDir, "conf/app.yaml")}, found)
+}
+
+func TestSuffixMatcher(t *testing.T) {
+	m, err := NewSuffixMatcher("sh", ".sql", "*.pem", "migrations/*.up")
+	assert.Nil(t, err)
+
+	assert.True(t, m.Match("bin/start.sh"))
+	assert.False(t, m.Match("bin/publish"))
+	assert.False(t, m.Match("fresh"))
+	assert.False(t, m.Match(".sh"))
+	assert.True(t, m.Match("db/001.sql"))
+	assert.True(t, m.Match("certs/server.pem"))
+	assert.True(t, m.Match("migrations/001.up"))
+	assert.False(t, m.Match("other/001.up"))
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 2:10
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BuildScheduler 여러 프로세스를 한번에 패키징할 때 바이너리를 공유한다
+// 같은 main 패키지는 한번만 컴파일하고, 이후 프로세스에는 컴파일된 바이너리를 복사한다
+type BuildScheduler struct {
+	dir      string
+	mutex    sync.Mutex
+	compiled map[string]*compiledBinary
+	compile  func(cmdRecord CmdRecord, workingDir string) error
+}
+
+type compiledBinary struct {
+	once sync.Once
+	dir  string
+	err  error
+}
+
+func NewBuildScheduler() (*BuildScheduler, error) {
+	dir, err := os.MkdirTemp("", "gofar_build")
+	if err != nil {
+		return nil, fmt.Errorf("fail to create build dir : %s", err.Error())
+	}
+	return &BuildScheduler{dir: dir, compiled: make(map[string]*compiledBinary), compile: compileCmdRecord}, nil
+}
+
+func (s *BuildScheduler) Close() {
+	_ = os.RemoveAll(s.dir)
+}
+
+// Prepare cmdRecord 바이너리를 (필요하면 컴파일하여) workingDir/platform 하위에 복사한다
+func (s *BuildScheduler) Prepare(cmdRecord CmdRecord, workingDir string) error {
+	s.mutex.Lock()
+	binary, ok := s.compiled[cmdRecord.Path]
+	if !ok {
+		binary = &compiledBinary{dir: filepath.Join(s.dir, fmt.Sprintf("%d", len(s.compiled)))}
+		s.compiled[cmdRecord.Path] = binary
+	}
+	s.mutex.Unlock()
+
+	binary.once.Do(func() {
+		binary.err = s.compile(cmdRecord, binary.dir)
+	})
+	if binary.err != nil {
+		return binary.err
+	}
+
+	fmt.Printf("\n>> copy shared binary %s...\n", cmdRecord.GetBinaryname())
+	for _, platform := range buildPlatformList.GetBuildPlatforms() {
+		rel := filepath.Join(PlatformDirName, platform.getPlatformDirectory(), platform.executableName(cmdRecord.GetBinaryname()))
+		target := filepath.Join(workingDir, rel)
+		err := EnsureDirectory(filepath.Dir(target))
+		if err != nil {
+			return err
+		}
+		err = CopyFile(filepath.Join(binary.dir, rel), target)
+		if err != nil {
+			return fmt.Errorf("fail to copy binary %s : %s", rel, err.Error())
+		}
+		_ = os.Chmod(target, 0755)
+	}
+	return nil
+}
+
+// PackageResult 프로세스별 패키징 결과
+type PackageResult struct {
+	Process string
+	FarPath string
+	Elapsed time.Duration
+	Err     error
+}
+
+// PackageProcesses 여러 프로세스를 하나의 BuildScheduler 로 순서대로 패키징하고 결과 요약을 출력한다
+// 특정 프로세스가 실패하더라도 나머지 프로세스의 패키징은 계속 진행한다
+func PackageProcesses(procNames []string) error {
+	scheduler, err := NewBuildScheduler()
+	if err != nil {
+		return err
+	}
+	defer scheduler.Close()
+
+	results := packageEach(procNames, func(procName string) (string, error) {
+		return packageProcess(procName, scheduler)
+	})
+	return printPackageSummary(results)
+}
+
+// packageEach 프로세스별로 pack 을 순서대로 수행하고 결과를 모은다
+func packageEach(procNames []string, pack func(procName string) (string, error)) []PackageResult {
+	results := make([]PackageResult, 0, len(procNames))
+	for _, procName := range procNames {
+		fmt.Printf("\n==================================================\n")
+		fmt.Printf("packaging %s\n", procName)
+		fmt.Printf("==================================================\n")
+
+		start := time.Now()
+		farPath, err := pack(procName)
+		results = append(results, PackageResult{Process: procName, FarPath: farPath, Elapsed: time.Since(start), Err: err})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gofar packaging fail : %s : %s\n", procName, err.Error())
+		}
+	}
+	return results
+}
+
+func packageProcess(procName string, scheduler *BuildScheduler) (string, error) {
+	ctx, err := NewBuildContext(procName)
+	if err != nil {
+		return "", err
+	}
+	ctx.Profile = profileName
+	ctx.scheduler = scheduler
+
+	ctx.Print()
+
+	err = ctx.Packaging()
+	if err != nil {
+		return "", err
+	}
+	return ctx.farPath, nil
+}
+
+func printPackageSummary(results []PackageResult) error {
+	fmt.Printf("\n>> summary\n")
+	failCount := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failCount++
+			fmt.Printf("%-24s FAIL     %s\n", result.Process, result.Err.Error())
+			continue
+		}
+		fmt.Printf("%-24s SUCCESS  %s (%.1fs)\n", result.Process, result.FarPath, result.Elapsed.Seconds())
+	}
+	fmt.Printf("total %d processes, %d success, %d fail\n", len(results), len(results)-failCount, failCount)
+
+	if failCount > 0 {
+		return fmt.Errorf("%d of %d processes failed", failCount, len(results))
+	}
+	return nil
+}
+
+// listConfiguredProcesses .gofar.yaml 의 processes 에 정의된 프로세스 이름 목록을 구한다
+func listConfiguredProcesses() ([]string, error) {
+	loadPlatform()
+
+	ctx := &BuildContext{}
+	err := determineProjectBaseDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := loadProjectConfig(ctx.ProjectBaseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	procNames := make([]string, 0, len(config.Processes))
+	for name := range config.Processes {
+		procNames = append(procNames, name)
+	}
+	if len(procNames) == 0 {
+		return nil, fmt.Errorf("processes not found in %s", filepath.Join(ctx.ProjectBaseDir, ProjectConfigFile))
+	}
+	sort.Strings(procNames)
+	return procNames, nil
+}
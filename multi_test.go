@@ -0,0 +1,132 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 2:30
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestBuildSchedulerPrepare(t *testing.T) {
+	saved := buildPlatformList
+	t.Cleanup(func() {
+		buildPlatformList = saved
+	})
+	local := PlatformItem{Os: runtime.GOOS, Arch: runtime.GOARCH}
+	other := PlatformItem{Os: "plan9", Arch: "386"}
+	buildPlatformList = YamlBuildPlatformConfig{Platforms: []PlatformItem{local, other}}
+
+	var mutex sync.Mutex
+	compileCount := make(map[string]int)
+	scheduler := &BuildScheduler{dir: t.TempDir(), compiled: make(map[string]*compiledBinary)}
+	scheduler.compile = func(cmdRecord CmdRecord, workingDir string) error {
+		mutex.Lock()
+		compileCount[cmdRecord.Path]++
+		mutex.Unlock()
+		for _, platform := range buildPlatformList.GetBuildPlatforms() {
+			target := filepath.Join(workingDir, PlatformDirName, platform.getPlatformDirectory(), platform.executableName(cmdRecord.GetBinaryname()))
+			err := EnsureDirectory(filepath.Dir(target))
+			if err != nil {
+				return err
+			}
+			err = os.WriteFile(target, []byte(platform.getPlatformDirectory()), 0644)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	hello := CmdRecord{Path: "/project/cmd/hello"}
+	worker := CmdRecord{Path: "/project/cmd/worker"}
+	workingDirs := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+
+	wg := sync.WaitGroup{}
+	for _, workingDir := range workingDirs {
+		wg.Add(1)
+		go func(dir string) {
+			defer wg.Done()
+			assert.Nil(t, scheduler.Prepare(hello, dir))
+		}(workingDir)
+	}
+	wg.Wait()
+	assert.Nil(t, scheduler.Prepare(worker, workingDirs[0]))
+
+	assert.Equal(t, 1, compileCount[hello.Path])
+	assert.Equal(t, 1, compileCount[worker.Path])
+
+	for _, workingDir := range workingDirs {
+		for _, platform := range []PlatformItem{local, other} {
+			target := filepath.Join(workingDir, PlatformDirName, platform.getPlatformDirectory(), platform.executableName("hello"))
+			data, err := os.ReadFile(target)
+			assert.Nil(t, err)
+			assert.Equal(t, platform.getPlatformDirectory(), string(data))
+
+			info, err := os.Stat(target)
+			assert.Nil(t, err)
+			assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+		}
+	}
+}
+
+func TestBuildSchedulerPrepareError(t *testing.T) {
+	compileCount := 0
+	scheduler := &BuildScheduler{dir: t.TempDir(), compiled: make(map[string]*compiledBinary)}
+	scheduler.compile = func(cmdRecord CmdRecord, workingDir string) error {
+		compileCount++
+		return fmt.Errorf("compile error")
+	}
+
+	hello := CmdRecord{Path: "/project/cmd/hello"}
+	assert.NotNil(t, scheduler.Prepare(hello, t.TempDir()))
+	assert.NotNil(t, scheduler.Prepare(hello, t.TempDir()))
+	assert.Equal(t, 1, compileCount)
+}
+
+func TestPackageEach(t *testing.T) {
+	packed := make([]string, 0)
+	results := packageEach([]string{"alpha", "beta", "gamma"}, func(procName string) (string, error) {
+		packed = append(packed, procName)
+		if procName == "beta" {
+			return "", fmt.Errorf("fail to build beta")
+		}
+		return "/far/" + procName + ".far", nil
+	})
+
+	assert.Equal(t, []string{"alpha", "beta", "gamma"}, packed)
+	assert.Equal(t, 3, len(results))
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "/far/alpha.far", results[0].FarPath)
+	assert.NotNil(t, results[1].Err)
+	assert.Nil(t, results[2].Err)
+	assert.Equal(t, "/far/gamma.far", results[2].FarPath)
+
+	err := printPackageSummary(results)
+	assert.NotNil(t, err)
+	assert.Equal(t, "1 of 3 processes failed", err.Error())
+
+	assert.Nil(t, printPackageSummary(results[:1]))
+}
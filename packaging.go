@@ -24,23 +24,49 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 )
 
-var usage = `usage: %s [option] process_name
+var usage = `usage: %s [option] process_name [process_name ...]
+usage: %s repack [option] far_file
+usage: %s resources --list process_name
 usage: %s version
 
 golang fatima package builder
 
 positional arguments:
-  process_name          process(program) name
+  process_name          process(program) name. several names package one far per process
 
 optional arguments:
   -c    CGO Enable
   -s    Strip library while CGO enable
+  -profile name
+        apply resources/profiles/<name> overlay
+  -release
+        release build (fail when secrets are found in resources)
+  -bin a,b
+        binaries(main package directory names) to include in the far
+  -all
+        package all processes defined in .gofar.yaml processes
+  -platform list
+        build only the given platforms (os/arch, os/arch/variant, group name in gofar.yaml or local)
+        e.g. -platform linux/amd64,linux/arm64 or -platform server
+  -skip-local
+        skip the local platform compile check when the local platform is not in platform_list
+  -changed-since rev
+        package only processes affected by changes in rev...HEAD
+        (all processes in .gofar.yaml when process_name is omitted)
 `
 
 var cgoEnable = false
 var stripEnable = false
+var profileName = ""
+var releaseBuild = false
+var binList = ""
+var allProcess = false
+var changedSince = ""
+var platformFilter = ""
+var skipLocalBuild = false
 var version = "2.4.0"
 
 func Gofar() {
@@ -49,28 +75,73 @@ func Gofar() {
 			fmt.Printf("gofar version %s\n", version)
 			return
 		}
+		if os.Args[1] == "repack" {
+			err := Repack(os.Args[2:])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "gofar repack fail : %s", err.Error())
+			}
+			return
+		}
+		if os.Args[1] == "resources" {
+			err := Resources(os.Args[2:])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "gofar resources fail : %s", err.Error())
+			}
+			return
+		}
 	}
 
 	flag.Usage = func() {
-		fmt.Printf(usage, os.Args[0], os.Args[0])
+		fmt.Printf(usage, os.Args[0], os.Args[0], os.Args[0], os.Args[0])
 	}
 
 	flag.BoolVar(&cgoEnable, "c", false, "CGO enable")
 	flag.BoolVar(&stripEnable, "s", false, "CGO enable")
+	flag.StringVar(&profileName, "profile", "", "resource profile")
+	flag.BoolVar(&releaseBuild, "release", false, "release build")
+	flag.StringVar(&binList, "bin", "", "binaries to include")
+	flag.BoolVar(&allProcess, "all", false, "package all processes")
+	flag.StringVar(&changedSince, "changed-since", "", "git revision")
+	flag.StringVar(&platformFilter, "platform", "", "platforms to build")
+	flag.StringVar(&platformFilter, "platforms", "", "platforms to build")
+	flag.BoolVar(&skipLocalBuild, "skip-local", false, "skip local platform compile check")
 
 	flag.Parse()
-	if len(flag.Args()) < 1 {
+	if len(flag.Args()) < 1 && !allProcess && len(changedSince) == 0 {
 		flag.Usage()
 		return
 	}
 
-	processName := flag.Args()[0]
+	procNames, err := resolveProcessNames(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "packaging error : %s", err.Error())
+		return
+	}
+	if len(procNames) == 0 {
+		fmt.Printf("\nno process affected since %s\n", changedSince)
+		return
+	}
+
+	// -all, -changed-since 를 사용하더라도 대상 프로세스가 하나이면 단일 프로세스로 패키징한다
+	if len(procNames) > 1 {
+		packageMultiProcess(procNames)
+		return
+	}
 
-	ctx, err := NewBuildContext(processName)
+	ctx, err := NewBuildContext(procNames[0])
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "packaging error : %s", err.Error())
 		return
 	}
+	ctx.Profile = profileName
+
+	if len(binList) > 0 {
+		err = ctx.SelectBinaries(strings.Split(binList, ","))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "packaging error : %s", err.Error())
+			return
+		}
+	}
 
 	ctx.Print()
 
@@ -79,3 +150,85 @@ func Gofar() {
 		fmt.Fprintf(os.Stderr, "gofar packaging fail : %s", err.Error())
 	}
 }
+
+// resolveProcessNames 패키징할 프로세스 이름 목록을 구한다
+// 프로세스 이름을 생략하면 .gofar.yaml 의 processes 를 사용하고, -changed-since 가 지정되면 영향을 받는 프로세스만 남긴다
+func resolveProcessNames(procNames []string) ([]string, error) {
+	if allProcess && len(procNames) > 0 {
+		return nil, fmt.Errorf("-all cannot be used with process names")
+	}
+
+	var err error
+	if len(procNames) == 0 {
+		procNames, err = listConfiguredProcesses()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(changedSince) > 0 {
+		procNames, err = filterChangedProcesses(procNames, changedSince)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return procNames, nil
+}
+
+// packageMultiProcess 여러 프로세스를 한번에 패키징한다
+func packageMultiProcess(procNames []string) {
+	if len(binList) > 0 {
+		fmt.Fprintf(os.Stderr, "packaging error : -bin cannot be used with multiple processes. use processes in %s\n", ProjectConfigFile)
+		return
+	}
+
+	err := PackageProcesses(procNames)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gofar packaging fail : %s", err.Error())
+	}
+}
+
+var resourcesUsage = `usage: %s resources [-profile name] --list process_name
+
+print resource files which will be packaged (dry run)
+
+optional arguments:
+  --list    list resource files
+  -profile name
+            apply resources/profiles/<name> overlay
+`
+
+// Resources resources 서브 커맨드를 처리한다
+func Resources(args []string) error {
+	fs := flag.NewFlagSet("resources", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf(resourcesUsage, os.Args[0])
+	}
+
+	var list bool
+	var profile string
+	fs.BoolVar(&list, "list", false, "list resource files")
+	fs.StringVar(&profile, "profile", "", "resource profile")
+	_ = fs.Parse(args)
+	if !list || fs.NArg() < 1 {
+		fs.Usage()
+		return nil
+	}
+
+	ctx, err := NewBuildContext(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	ctx.Profile = profile
+
+	resourceList, err := ctx.ListResourceFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resourceList {
+		fmt.Printf("%s <- %s\n", resource.Target, resource.Source)
+	}
+	fmt.Printf("total %d resource files\n", len(resourceList))
+	return nil
+}
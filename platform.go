@@ -22,13 +22,17 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
+	"sync"
 )
 
 const (
@@ -61,6 +65,125 @@ func loadPlatform() {
 	if err != nil {
 		panic(fmt.Errorf("invalid gofar platform yaml file : %s", err.Error()))
 	}
+
+	err = validatePlatformList(buildPlatformList.Platforms)
+	if err != nil {
+		panic(fmt.Errorf("invalid gofar platform yaml file : %s", err.Error()))
+	}
+
+	err = buildPlatformList.validateGroups()
+	if err != nil {
+		panic(fmt.Errorf("invalid gofar platform yaml file : %s", err.Error()))
+	}
+
+	warnings := checkVariantFallback(buildPlatformList.Platforms)
+	distList, err := loadDistPlatforms()
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("skip platform support check. %s", err.Error()))
+	} else {
+		supportWarnings, err := checkPlatformSupport(buildPlatformList.Platforms, distList, cgoEnable)
+		if err != nil {
+			panic(fmt.Errorf("invalid gofar platform yaml file : %s", err.Error()))
+		}
+		warnings = append(warnings, supportWarnings...)
+	}
+	// 여러 프로세스를 패키징하는 경우에도 경고는 한번만 출력한다
+	platformWarningOnce.Do(func() {
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "warning : %s\n", warning)
+		}
+	})
+}
+
+var platformWarningOnce sync.Once
+
+// DistPlatform go tool dist list -json 의 항목
+type DistPlatform struct {
+	GOOS         string
+	GOARCH       string
+	CgoSupported bool
+	FirstClass   bool
+}
+
+var distPlatformList []DistPlatform
+var distPlatformErr error
+var distPlatformOnce sync.Once
+
+// loadDistPlatforms go tool dist list -json 으로 go 툴체인이 지원하는 플랫폼 목록을 구한다. 결과는 한번만 조회한다
+func loadDistPlatforms() ([]DistPlatform, error) {
+	distPlatformOnce.Do(func() {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command("go", "tool", "dist", "list", "-json")
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		if err != nil {
+			distPlatformErr = fmt.Errorf("go tool dist list error : %s\n%s", err.Error(), stderr.String())
+			return
+		}
+		err = json.Unmarshal(stdout.Bytes(), &distPlatformList)
+		if err != nil {
+			distPlatformErr = fmt.Errorf("invalid go tool dist list output : %s", err.Error())
+		}
+	})
+	return distPlatformList, distPlatformErr
+}
+
+// checkPlatformSupport 플랫폼 목록을 go 툴체인이 지원하는 플랫폼 목록과 비교한다
+// 지원하지 않는 os/arch 는 에러로, secondary port 와 cgo 를 지원하지 않는 플랫폼의 cgo 빌드는 경고로 반환한다
+// 플랫폼의 env 에 CGO_ENABLED 가 지정되어 있으면 -c 옵션보다 우선한다
+func checkPlatformSupport(platforms []PlatformItem, distList []DistPlatform, cgo bool) ([]string, error) {
+	distMap := make(map[string]DistPlatform)
+	for _, dist := range distList {
+		distMap[dist.GOOS+"/"+dist.GOARCH] = dist
+	}
+
+	warnings := make([]string, 0)
+	for _, platform := range platforms {
+		name := platform.Os + "/" + platform.Arch
+		dist, ok := distMap[name]
+		if !ok {
+			return nil, fmt.Errorf("platform %s is not supported by go toolchain. check 'go tool dist list'", name)
+		}
+		if !dist.FirstClass {
+			warnings = append(warnings, fmt.Sprintf("platform %s is a secondary port (not first class)", name))
+		}
+		if cgoEnabled(platform.Env, cgo) && !dist.CgoSupported {
+			warnings = append(warnings, fmt.Sprintf("platform %s does not support cgo", name))
+		}
+	}
+	return warnings, nil
+}
+
+// checkVariantFallback variant 항목만 있고 기본(<os>_<arch>) 항목이 없는 os/arch 를 경고로 반환한다
+// 배포 환경의 호스트가 지원하는 variant 가 없으면 사용할 바이너리가 없기 때문이다
+func checkVariantFallback(platforms []PlatformItem) []string {
+	baseSet := make(map[string]struct{})
+	for _, platform := range platforms {
+		if len(platform.Variant) == 0 {
+			baseSet[platform.Os+"/"+platform.Arch] = struct{}{}
+		}
+	}
+
+	warnings := make([]string, 0)
+	for _, platform := range platforms {
+		name := platform.Os + "/" + platform.Arch
+		if _, ok := baseSet[name]; ok || len(platform.Variant) == 0 {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("platform %s has only variant entries. hosts without a matching variant have no fallback binary (add os: %s, arch: %s)",
+			name, platform.Os, platform.Arch))
+		baseSet[name] = struct{}{}
+	}
+	return warnings
+}
+
+// cgoEnabled 플랫폼의 cgo 빌드 여부. env 에 CGO_ENABLED 가 지정되어 있으면 -c 옵션보다 우선한다
+func cgoEnabled(env map[string]string, cgo bool) bool {
+	if value, ok := env["CGO_ENABLED"]; ok {
+		return value == "1"
+	}
+	return cgo
 }
 
 // prepareDefaultPlatformFile 기본 빌드 플랫폼 정보 파일을 생성한다
@@ -104,16 +227,148 @@ platform_list:
 */
 type YamlBuildPlatformConfig struct {
 	Platforms []PlatformItem `yaml:"platform_list"`
+	// Groups -platform 옵션으로 선택할 수 있는 플랫폼 그룹. 항목은 os/arch 또는 os/arch/variant 형태이다
+	Groups map[string][]string `yaml:"groups,omitempty"`
+}
+
+const localPlatformName = "local"
+
+// validateGroups 그룹 이름과 그룹의 플랫폼 항목들을 검사한다
+func (y YamlBuildPlatformConfig) validateGroups() error {
+	for name, entries := range y.Groups {
+		if len(name) == 0 || name == localPlatformName || strings.ContainsAny(name, "/,") {
+			return fmt.Errorf("invalid platform group name '%s'", name)
+		}
+		for _, entry := range entries {
+			_, err := y.matchPlatforms(entry)
+			if err != nil {
+				return fmt.Errorf("platform group %s : %s", name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// matchPlatforms os/arch 또는 os/arch/variant 항목에 해당하는 플랫폼을 platform_list 에서 찾는다
+// os/arch 는 해당 os/arch 의 모든 variant 를 포함한다
+func (y YamlBuildPlatformConfig) matchPlatforms(entry string) ([]PlatformItem, error) {
+	if entry == localPlatformName {
+		return []PlatformItem{y.GetLocalPlatform()}, nil
+	}
+
+	parts := strings.Split(entry, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid platform '%s' (os/arch or os/arch/variant)", entry)
+	}
+
+	list := make([]PlatformItem, 0)
+	for _, platform := range y.Platforms {
+		if platform.Os != parts[0] || platform.Arch != parts[1] {
+			continue
+		}
+		if len(parts) == 3 {
+			wanted := PlatformItem{Arch: platform.Arch, Variant: parts[2]}
+			if len(platform.Variant) == 0 || platform.variantDirectory() != wanted.variantDirectory() {
+				continue
+			}
+		}
+		list = append(list, platform)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("platform %s is not in platform_list", entry)
+	}
+	return list, nil
+}
+
+// SelectPlatforms filter(콤마로 구분된 os/arch, os/arch/variant, 그룹 이름 또는 local)에 해당하는 플랫폼만 빌드 대상으로 남긴다
+func (y *YamlBuildPlatformConfig) SelectPlatforms(filter string) error {
+	selected := make(map[string]struct{})
+	for _, entry := range strings.Split(filter, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		entries := []string{entry}
+		if group, ok := y.Groups[entry]; ok {
+			entries = group
+		}
+		for _, e := range entries {
+			list, err := y.matchPlatforms(e)
+			if err != nil {
+				return err
+			}
+			for _, platform := range list {
+				selected[platform.getPlatformDirectory()] = struct{}{}
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no platform selected by '%s'", filter)
+	}
+
+	platforms := make([]PlatformItem, 0, len(selected))
+	local := y.GetLocalPlatform()
+	if _, ok := selected[local.getPlatformDirectory()]; ok && !y.contains(local) {
+		// local 로 선택한 로컬 플랫폼이 platform_list 에 없는 경우
+		platforms = append(platforms, local)
+	}
+	for _, platform := range y.Platforms {
+		if _, ok := selected[platform.getPlatformDirectory()]; ok {
+			platforms = append(platforms, platform)
+		}
+	}
+	y.Platforms = platforms
+	return nil
+}
+
+func (y YamlBuildPlatformConfig) contains(platform PlatformItem) bool {
+	for _, p := range y.Platforms {
+		if p.getPlatformDirectory() == platform.getPlatformDirectory() {
+			return true
+		}
+	}
+	return false
 }
 
+// GetLocalPlatform 로컬 플랫폼을 구한다. 플랫폼 목록에 로컬 플랫폼이 있으면 해당 빌드 설정을 사용한다
+// variant 가 지정된 항목은 로컬 플랫폼이 아닌 추가 플랫폼으로 취급한다
 func (y YamlBuildPlatformConfig) GetLocalPlatform() PlatformItem {
+	for _, platform := range y.Platforms {
+		if platform.isLocal() {
+			return platform
+		}
+	}
 	return PlatformItem{Os: runtime.GOOS, Arch: runtime.GOARCH}
 }
 
+// IsLocalTarget 로컬 플랫폼이 빌드 대상(platform_list)에 포함되는지 여부
+// platform_list 가 비어있으면 로컬 플랫폼만 빌드한다
+func (y YamlBuildPlatformConfig) IsLocalTarget() bool {
+	if len(y.Platforms) == 0 {
+		return true
+	}
+	for _, platform := range y.Platforms {
+		if platform.isLocal() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBuildPlatforms far 에 포함되는 빌드 대상 플랫폼 목록을 구한다
+// 로컬 플랫폼은 platform_list 에 포함된 경우에만 대상이 된다
+func (y YamlBuildPlatformConfig) GetBuildPlatforms() []PlatformItem {
+	if !y.IsLocalTarget() {
+		return y.GetAdditionalPlatforms()
+	}
+	return append([]PlatformItem{y.GetLocalPlatform()}, y.GetAdditionalPlatforms()...)
+}
+
 func (y YamlBuildPlatformConfig) GetAdditionalPlatforms() []PlatformItem {
 	list := make([]PlatformItem, 0)
 	for _, platform := range y.Platforms {
-		if platform.Os == runtime.GOOS && platform.Arch == runtime.GOARCH {
+		if platform.isLocal() {
 			continue
 		}
 		list = append(list, platform)
@@ -122,14 +377,155 @@ func (y YamlBuildPlatformConfig) GetAdditionalPlatforms() []PlatformItem {
 	return list
 }
 
+// PlatformItem 빌드 대상 플랫폼과 플랫폼별 빌드 설정
+//
+//	platform_list:
+//	  - os: linux
+//	    arch: amd64
+//	    env:
+//	      GOAMD64: v3
+//	    tags: [netgo]
+//	    ldflags: -X main.edition=server
+//	  - os: linux
+//	    arch: arm
+//	    variant: v7
 type PlatformItem struct {
 	Os   string `yaml:"os"`
 	Arch string `yaml:"arch"`
-	CC   string `yaml:"cc,omitempty"`
+	// Variant 같은 os/arch 의 세부 타겟 (GOARM, GOAMD64 등). platform/<os>_<arch>_<variant> 디렉토리로 구분된다
+	Variant string `yaml:"variant,omitempty"`
+	CC      string `yaml:"cc,omitempty"`
+	// Env go build 시 추가할 환경변수 (CGO_CFLAGS, CGO_LDFLAGS, PKG_CONFIG_PATH, GOAMD64, GOARM 등)
+	Env       map[string]string `yaml:"env,omitempty"`
+	Tags      []string          `yaml:"tags,omitempty"`
+	Ldflags   string            `yaml:"ldflags,omitempty"`
+	Gcflags   string            `yaml:"gcflags,omitempty"`
+	Buildmode string            `yaml:"buildmode,omitempty"`
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var variantPattern = regexp.MustCompile(`^[A-Za-z0-9.]+$`)
+
+var armVariantPattern = regexp.MustCompile(`^v?[5-7]$`)
+
+// platformVariantEnv arch 별 variant 를 지정하는 환경변수
+var platformVariantEnv = map[string]string{
+	"arm":      "GOARM",
+	"arm64":    "GOARM64",
+	"amd64":    "GOAMD64",
+	"386":      "GO386",
+	"mips":     "GOMIPS",
+	"mipsle":   "GOMIPS",
+	"mips64":   "GOMIPS64",
+	"mips64le": "GOMIPS64",
+	"ppc64":    "GOPPC64",
+	"ppc64le":  "GOPPC64",
+	"riscv64":  "GORISCV64",
+}
+
+func (p PlatformItem) isLocal() bool {
+	return p.Os == runtime.GOOS && p.Arch == runtime.GOARCH && len(p.Variant) == 0
+}
+
+// variantDirectory 디렉토리명에 사용할 variant. arm 은 GOARM 값(5, 6, 7)에 'v' 를 붙인다
+func (p PlatformItem) variantDirectory() string {
+	if p.Arch == "arm" {
+		return "v" + strings.TrimPrefix(p.Variant, "v")
+	}
+	return p.Variant
+}
+
+// variantEnvValue variant 환경변수 값. arm 은 'v' 를 제외한 숫자만 사용한다
+func (p PlatformItem) variantEnvValue() string {
+	if p.Arch == "arm" {
+		return strings.TrimPrefix(p.Variant, "v")
+	}
+	return p.Variant
+}
+
+// buildEnv go build 시 적용할 환경변수. variant 는 arch 에 해당하는 환경변수로 변환된다
+func (p PlatformItem) buildEnv() map[string]string {
+	if len(p.Variant) == 0 {
+		return p.Env
+	}
+
+	env := make(map[string]string)
+	for k, v := range p.Env {
+		env[k] = v
+	}
+	env[platformVariantEnv[p.Arch]] = p.variantEnvValue()
+	return env
+}
+
+// validate 플랫폼별 빌드 설정을 검사한다
+func (p PlatformItem) validate() error {
+	for name := range p.Env {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("platform %s : invalid env name %s", p.getPlatformDirectory(), name)
+		}
+		if name == "GOOS" || name == "GOARCH" {
+			return fmt.Errorf("platform %s : %s cannot be set by env. use os/arch", p.getPlatformDirectory(), name)
+		}
+	}
+	for _, tag := range p.Tags {
+		if len(tag) == 0 || strings.ContainsAny(tag, ", \t") {
+			return fmt.Errorf("platform %s : invalid build tag '%s'", p.getPlatformDirectory(), tag)
+		}
+	}
+
+	if len(p.Variant) > 0 {
+		envName, ok := platformVariantEnv[p.Arch]
+		if !ok {
+			return fmt.Errorf("platform %s : variant is not supported for %s", p.getPlatformDirectory(), p.Arch)
+		}
+		if !variantPattern.MatchString(p.Variant) {
+			return fmt.Errorf("platform %s : invalid variant '%s'", p.getPlatformDirectory(), p.Variant)
+		}
+		if p.Arch == "arm" && !armVariantPattern.MatchString(p.Variant) {
+			return fmt.Errorf("platform %s : invalid arm variant '%s' (v5, v6 or v7)", p.getPlatformDirectory(), p.Variant)
+		}
+		if _, ok := p.Env[envName]; ok {
+			return fmt.Errorf("platform %s : %s is set by both variant and env", p.getPlatformDirectory(), envName)
+		}
+	}
+	return nil
+}
+
+// validatePlatformList 플랫폼 항목들을 검사하고 같은 디렉토리로 빌드되는 중복 항목이 없는지 확인한다
+func validatePlatformList(platforms []PlatformItem) error {
+	dirs := make(map[string]struct{})
+	for _, platform := range platforms {
+		err := platform.validate()
+		if err != nil {
+			return err
+		}
+		dir := platform.getPlatformDirectory()
+		if _, ok := dirs[dir]; ok {
+			return fmt.Errorf("platform %s is duplicated", dir)
+		}
+		dirs[dir] = struct{}{}
+	}
+	return nil
+}
+
+// executableName 플랫폼별 실행 파일 이름. windows 는 .exe, wasm 은 .wasm 확장자를 붙인다
+func (p PlatformItem) executableName(name string) string {
+	switch {
+	case p.Os == "windows":
+		return name + ".exe"
+	case p.Arch == "wasm":
+		return name + ".wasm"
+	}
+	return name
 }
 
 // getPlatformDirectory os_arch 형태의 플랫폼 구분 디렉토리명을 구한다
+// variant 가 있으면 os_arch_variant 형태가 된다 (linux_arm_v7, linux_amd64_v3)
 func (p PlatformItem) getPlatformDirectory() string {
+	if len(p.Variant) > 0 {
+		return fmt.Sprintf("%s_%s_%s", p.Os, p.Arch, p.variantDirectory())
+	}
 	return fmt.Sprintf("%s_%s", p.Os, p.Arch)
 }
 
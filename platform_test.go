@@ -0,0 +1,231 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 5:40
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestBuildCompileCommand(t *testing.T) {
+	platform := PlatformItem{Os: "linux", Arch: "amd64"}
+	request := createCompileRequest(platform, CmdRecord{Path: "/p/cmd/hello"}, "/w", "")
+	assert.Equal(t, "GOOS=linux GOARCH=amd64 go build -o /w/bin", buildCompileCommand(request, "/w/bin"))
+
+	platform.Env = map[string]string{"GOAMD64": "v3", "CGO_CFLAGS": "-O2 -I/opt/include"}
+	platform.Tags = []string{"netgo", "osusergo"}
+	platform.Ldflags = "-X main.edition=server"
+	platform.Gcflags = "all=-N -l"
+	platform.Buildmode = "pie"
+	request = createCompileRequest(platform, CmdRecord{Path: "/p/cmd/hello"}, "/w", "")
+	assert.Equal(t, "GOOS=linux GOARCH=amd64 CGO_CFLAGS='-O2 -I/opt/include' GOAMD64=v3 go build -o /w/bin"+
+		" -tags=netgo,osusergo -ldflags='-X main.edition=server' -gcflags='all=-N -l' -buildmode=pie",
+		buildCompileCommand(request, "/w/bin"))
+
+	savedCgo, savedStrip := cgoEnable, stripEnable
+	t.Cleanup(func() {
+		cgoEnable, stripEnable = savedCgo, savedStrip
+	})
+	cgoEnable, stripEnable = true, true
+	request = createCompileRequest(PlatformItem{Os: "linux", Arch: "arm64", Ldflags: "-X a.b=c"}, CmdRecord{Path: "/p/cmd/hello"}, "/w", "aarch64-gcc")
+	assert.Equal(t, "CGO_ENABLED=1 CC=aarch64-gcc GOOS=linux GOARCH=arm64 go build -o /w/bin -ldflags='-s -w -X a.b=c'",
+		buildCompileCommand(request, "/w/bin"))
+
+	assert.Equal(t, "CGO_ENABLED=1 CC=aarch64-gcc GOOS=linux GOARCH=arm64 go build -o '/my work/bin' -ldflags='-s -w -X a.b=c'",
+		buildCompileCommand(request, "/my work/bin"))
+
+	// 플랫폼 env 의 CGO_ENABLED 가 -c 옵션보다 우선한다
+	request = createCompileRequest(PlatformItem{Os: "linux", Arch: "arm64", Env: map[string]string{"CGO_ENABLED": "0"}}, CmdRecord{Path: "/p/cmd/hello"}, "/w", "aarch64-gcc")
+	assert.Equal(t, "GOOS=linux GOARCH=arm64 CGO_ENABLED=0 go build -o /w/bin", buildCompileCommand(request, "/w/bin"))
+
+	cgoEnable = false
+	request = createCompileRequest(PlatformItem{Os: "linux", Arch: "arm64", Env: map[string]string{"CGO_ENABLED": "1"}}, CmdRecord{Path: "/p/cmd/hello"}, "/w", "aarch64-gcc")
+	assert.Equal(t, "CC=aarch64-gcc GOOS=linux GOARCH=arm64 CGO_ENABLED=1 go build -o /w/bin -ldflags='-s -w'", buildCompileCommand(request, "/w/bin"))
+}
+
+func TestExecutableName(t *testing.T) {
+	assert.Equal(t, "hello.exe", PlatformItem{Os: "windows", Arch: "amd64"}.executableName("hello"))
+	assert.Equal(t, "hello.wasm", PlatformItem{Os: "js", Arch: "wasm"}.executableName("hello"))
+	assert.Equal(t, "hello", PlatformItem{Os: "linux", Arch: "arm64"}.executableName("hello"))
+}
+
+func TestPlatformItemValidate(t *testing.T) {
+	assert.Nil(t, PlatformItem{Os: "linux", Arch: "amd64", Env: map[string]string{"GOAMD64": "v3"}}.validate())
+	assert.NotNil(t, PlatformItem{Os: "linux", Arch: "amd64", Env: map[string]string{"GOOS": "darwin"}}.validate())
+	assert.NotNil(t, PlatformItem{Os: "linux", Arch: "amd64", Env: map[string]string{"A B": "x"}}.validate())
+	assert.NotNil(t, PlatformItem{Os: "linux", Arch: "amd64", Tags: []string{"a,b"}}.validate())
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
+
+func TestPlatformVariant(t *testing.T) {
+	armV7 := PlatformItem{Os: "linux", Arch: "arm", Variant: "7"}
+	assert.Nil(t, armV7.validate())
+	assert.Equal(t, "linux_arm_v7", armV7.getPlatformDirectory())
+	assert.Equal(t, map[string]string{"GOARM": "7"}, armV7.buildEnv())
+
+	amd64V3 := PlatformItem{Os: "linux", Arch: "amd64", Variant: "v3", Env: map[string]string{"CGO_CFLAGS": "-O2"}}
+	assert.Nil(t, amd64V3.validate())
+	assert.Equal(t, "linux_amd64_v3", amd64V3.getPlatformDirectory())
+	assert.Equal(t, map[string]string{"GOAMD64": "v3", "CGO_CFLAGS": "-O2"}, amd64V3.buildEnv())
+	request := createCompileRequest(amd64V3, CmdRecord{Path: "/p/cmd/hello"}, "/w", "")
+	assert.Equal(t, "/w/platform/linux_amd64_v3", request.TargetDir)
+
+	assert.NotNil(t, PlatformItem{Os: "linux", Arch: "arm", Variant: "v8"}.validate())
+	assert.NotNil(t, PlatformItem{Os: "linux", Arch: "s390x", Variant: "z15"}.validate())
+	assert.NotNil(t, PlatformItem{Os: "linux", Arch: "amd64", Variant: "v3", Env: map[string]string{"GOAMD64": "v2"}}.validate())
+
+	assert.Nil(t, validatePlatformList([]PlatformItem{{Os: "linux", Arch: "arm", Variant: "v6"}, armV7, {Os: "linux", Arch: "arm"}}))
+	assert.NotNil(t, validatePlatformList([]PlatformItem{{Os: "linux", Arch: "arm", Variant: "v7"}, armV7}))
+}
+
+func TestCheckVariantFallback(t *testing.T) {
+	armV7 := PlatformItem{Os: "linux", Arch: "arm", Variant: "7"}
+	armV6 := PlatformItem{Os: "linux", Arch: "arm", Variant: "6"}
+	arm := PlatformItem{Os: "linux", Arch: "arm"}
+
+	assert.Empty(t, checkVariantFallback([]PlatformItem{arm, armV7}))
+	assert.Empty(t, checkVariantFallback([]PlatformItem{armV7, armV6, arm}))
+
+	warnings := checkVariantFallback([]PlatformItem{armV7, armV6, {Os: "linux", Arch: "amd64"}})
+	assert.Equal(t, 1, len(warnings))
+	assert.Contains(t, warnings[0], "platform linux/arm has only variant entries")
+}
+
+func TestCheckPlatformSupport(t *testing.T) {
+	distList := []DistPlatform{
+		{GOOS: "linux", GOARCH: "amd64", CgoSupported: true, FirstClass: true},
+		{GOOS: "linux", GOARCH: "riscv64", CgoSupported: true, FirstClass: false},
+		{GOOS: "js", GOARCH: "wasm", CgoSupported: false, FirstClass: false},
+	}
+
+	warnings, err := checkPlatformSupport([]PlatformItem{{Os: "linux", Arch: "amd64"}}, distList, true)
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+
+	warnings, err = checkPlatformSupport([]PlatformItem{{Os: "linux", Arch: "riscv64"}, {Os: "js", Arch: "wasm"}}, distList, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(warnings))
+
+	_, err = checkPlatformSupport([]PlatformItem{{Os: "linux", Arch: "amd46"}}, distList, false)
+	assert.NotNil(t, err)
+
+	wasmCgo := PlatformItem{Os: "js", Arch: "wasm", Env: map[string]string{"CGO_ENABLED": "1"}}
+	warnings, err = checkPlatformSupport([]PlatformItem{wasmCgo}, distList, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"platform js/wasm is a secondary port (not first class)", "platform js/wasm does not support cgo"}, warnings)
+
+	wasmCgo.Env["CGO_ENABLED"] = "0"
+	warnings, err = checkPlatformSupport([]PlatformItem{wasmCgo}, distList, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"platform js/wasm is a secondary port (not first class)"}, warnings)
+
+	// 실제 go 툴체인의 목록에 로컬 플랫폼이 포함되어야 한다
+	distList, err = loadDistPlatforms()
+	assert.Nil(t, err)
+	_, err = checkPlatformSupport([]PlatformItem{buildPlatformList.GetLocalPlatform()}, distList, false)
+	assert.Nil(t, err)
+}
+
+func TestSelectPlatforms(t *testing.T) {
+	newConfig := func() YamlBuildPlatformConfig {
+		return YamlBuildPlatformConfig{
+			Platforms: []PlatformItem{
+				{Os: "linux", Arch: "amd64"},
+				{Os: "linux", Arch: "arm64"},
+				{Os: "linux", Arch: "arm", Variant: "v6"},
+				{Os: "linux", Arch: "arm", Variant: "v7"},
+				{Os: "windows", Arch: "amd64"},
+			},
+			Groups: map[string][]string{
+				"server": {"linux/amd64", "linux/arm64"},
+				"edge":   {"linux/arm/v7"},
+			},
+		}
+	}
+	dirs := func(config YamlBuildPlatformConfig) []string {
+		list := make([]string, 0)
+		for _, platform := range config.Platforms {
+			list = append(list, platform.getPlatformDirectory())
+		}
+		return list
+	}
+
+	config := newConfig()
+	assert.Nil(t, config.validateGroups())
+	assert.Nil(t, config.SelectPlatforms("server"))
+	assert.Equal(t, []string{"linux_amd64", "linux_arm64"}, dirs(config))
+
+	config = newConfig()
+	assert.Nil(t, config.SelectPlatforms("windows/amd64, edge"))
+	assert.Equal(t, []string{"linux_arm_v7", "windows_amd64"}, dirs(config))
+
+	config = newConfig()
+	assert.Nil(t, config.SelectPlatforms("linux/arm"))
+	assert.Equal(t, []string{"linux_arm_v6", "linux_arm_v7"}, dirs(config))
+
+	config = newConfig()
+	assert.NotNil(t, config.SelectPlatforms("linux/386"))
+	assert.NotNil(t, config.SelectPlatforms("linux"))
+	assert.NotNil(t, config.SelectPlatforms(","))
+
+	config = newConfig()
+	config.Groups["broken"] = []string{"darwin/arm64"}
+	assert.NotNil(t, config.validateGroups())
+}
+
+func TestGetBuildPlatforms(t *testing.T) {
+	local := PlatformItem{Os: runtime.GOOS, Arch: runtime.GOARCH}
+	other := PlatformItem{Os: "plan9", Arch: "386"}
+
+	config := YamlBuildPlatformConfig{}
+	assert.True(t, config.IsLocalTarget())
+	assert.Equal(t, []PlatformItem{local}, config.GetBuildPlatforms())
+
+	config = YamlBuildPlatformConfig{Platforms: []PlatformItem{other}}
+	assert.False(t, config.IsLocalTarget())
+	assert.Equal(t, []PlatformItem{other}, config.GetBuildPlatforms())
+
+	config = YamlBuildPlatformConfig{Platforms: []PlatformItem{other, local}}
+	assert.True(t, config.IsLocalTarget())
+	assert.Equal(t, []PlatformItem{local, other}, config.GetBuildPlatforms())
+
+	assert.Nil(t, config.SelectPlatforms("plan9/386"))
+	assert.False(t, config.IsLocalTarget())
+}
+
+func TestVerifyCompiledBinary(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "libhello.a")
+	assert.Nil(t, os.WriteFile(archive, []byte("!<arch>\n"), 0644))
+
+	request := BinCompileRequest{Os: "linux", Arch: "amd64"}
+	assert.NotNil(t, verifyCompiledBinary(request, archive))
+
+	request.Buildmode = "c-archive"
+	assert.Nil(t, verifyCompiledBinary(request, archive))
+	request.Buildmode = "plugin"
+	assert.Nil(t, verifyCompiledBinary(request, archive))
+
+	request.Buildmode = "pie"
+	assert.NotNil(t, verifyCompiledBinary(request, archive))
+}
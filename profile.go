@@ -0,0 +1,84 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 6:02
+ */
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	profilesDirname = "profiles"
+)
+
+// profileDir resources/profiles/<profile> 디렉토리 경로
+func (b *BuildContext) profileDir() string {
+	return filepath.Join(b.ResourceDir, profilesDirname, b.Profile)
+}
+
+// checkProfile 지정된 profile 의 overlay 디렉토리가 존재하는지 확인한다
+func (b *BuildContext) checkProfile() error {
+	if len(b.Profile) == 0 {
+		return nil
+	}
+
+	if strings.ContainsAny(b.Profile, "/\\") || strings.HasPrefix(b.Profile, ".") {
+		return fmt.Errorf("invalid profile name : %s", b.Profile)
+	}
+
+	if len(b.ResourceDir) == 0 {
+		return fmt.Errorf("profile %s requires %s/%s/%s directory", b.Profile, resourceDirname, profilesDirname, b.Profile)
+	}
+
+	err := CheckDirExist(b.profileDir())
+	if err != nil {
+		return fmt.Errorf("invalid profile %s : %s", b.Profile, err.Error())
+	}
+	return nil
+}
+
+// farName far 파일명. 프로젝트 설정에 profile.far_name 이 지정되면 <process>-<profile>.far 형태로 만든다
+func (b *BuildContext) farName() string {
+	if len(b.Profile) > 0 && b.project.Profile.FarName {
+		return fmt.Sprintf("%s-%s.far", b.ExposeProcessName, b.Profile)
+	}
+	return fmt.Sprintf("%s.far", b.ExposeProcessName)
+}
+
+// overlayResourceFiles base 목록에 overlay 목록을 덮어쓴 결과를 구한다
+func overlayResourceFiles(base, overlay []ResourceFile) []ResourceFile {
+	index := make(map[string]int)
+	result := make([]ResourceFile, 0, len(base)+len(overlay))
+	for _, r := range base {
+		index[r.Target] = len(result)
+		result = append(result, r)
+	}
+	for _, r := range overlay {
+		if i, ok := index[r.Target]; ok {
+			result[i] = r
+			continue
+		}
+		index[r.Target] = len(result)
+		result = append(result, r)
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 2:40
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverlayResourceFiles(t *testing.T) {
+	base := []ResourceFile{
+		{Source: "/r/application.properties", Target: "application.properties"},
+		{Source: "/r/log.xml", Target: "log.xml"},
+	}
+	overlay := []ResourceFile{
+		{Source: "/r/profiles/prod/application.properties", Target: "application.properties"},
+		{Source: "/r/profiles/prod/prod.yaml", Target: "prod.yaml"},
+	}
+
+	assert.Equal(t, []ResourceFile{
+		{Source: "/r/profiles/prod/application.properties", Target: "application.properties"},
+		{Source: "/r/log.xml", Target: "log.xml"},
+		{Source: "/r/profiles/prod/prod.yaml", Target: "prod.yaml"},
+	}, overlayResourceFiles(base, overlay))
+}
+
+func TestLoadProfileResources(t *testing.T) {
+	resourceDir := t.TempDir()
+	writeTestSources(t, resourceDir, map[string]string{
+		"application.properties":                "main.port=8080\n",
+		"log.xml":                               "<log/>\n",
+		"profiles/prod/application.properties":  "main.port=80\n",
+		"profiles/prod/prod.yaml":               "replicas: 3\n",
+		"profiles/stage/application.properties": "main.port=8081\n",
+	})
+
+	ctx := &BuildContext{ExposeProcessName: "hello", ResourceDir: resourceDir, Profile: "prod", workingDir: t.TempDir()}
+	assert.Nil(t, ctx.loadResourceFiles())
+
+	// 기존 파일은 profile 의 파일로 교체된다
+	data, err := os.ReadFile(filepath.Join(ctx.workingDir, "application.properties"))
+	assert.Nil(t, err)
+	assert.Equal(t, "main.port=80\n", string(data))
+
+	// profile 에만 있는 파일은 추가된다
+	data, err = os.ReadFile(filepath.Join(ctx.workingDir, "prod.yaml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "replicas: 3\n", string(data))
+
+	data, err = os.ReadFile(filepath.Join(ctx.workingDir, "log.xml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "<log/>\n", string(data))
+
+	// profiles 디렉토리는 far 에 포함되지 않는다
+	assert.NotNil(t, CheckDirExist(filepath.Join(ctx.workingDir, profilesDirname)))
+}
+
+func TestUnknownProfile(t *testing.T) {
+	resourceDir := t.TempDir()
+	writeTestSources(t, resourceDir, map[string]string{
+		"application.properties":               "main.port=8080\n",
+		"profiles/prod/application.properties": "main.port=80\n",
+	})
+
+	ctx := &BuildContext{ExposeProcessName: "hello", ResourceDir: resourceDir, Profile: "qa", workingDir: t.TempDir()}
+	err := ctx.loadResourceFiles()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid profile qa")
+
+	_, err = ctx.ListResourceFiles()
+	assert.NotNil(t, err)
+
+	ctx.Profile = "../prod"
+	assert.NotNil(t, ctx.checkProfile())
+
+	ctx.Profile = "prod"
+	ctx.ResourceDir = ""
+	assert.NotNil(t, ctx.checkProfile())
+}
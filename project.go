@@ -0,0 +1,188 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 4:10
+ */
+
+package main
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+)
+
+const (
+	ProjectConfigFile = ".gofar.yaml"
+)
+
+// ProjectConfig 프로젝트별 gofar 설정으로 프로젝트 base 디렉토리의 .gofar.yaml 파일로 관리한다
+//
+//	process:
+//	  type: USER_INTERACTIVE
+//	  package: script
+//	resource:
+//	  layout: preserve
+//	  root: conf
+//	  symlink: preserve
+//	  platform:
+//	    linux_amd64: native/linux-x64
+//	  extra_suffix:
+//	    - sql
+//	    - "*.pem"
+//	  include:
+//	    - conf/**
+//	  exclude:
+//	    - "*.sample.yaml"
+//	profile:
+//	  far_name: true
+//	template:
+//	  files:
+//	    - application.properties
+//	  vars:
+//	    app.owner: platform-team
+//	properties:
+//	  merge: true
+//	  required:
+//	    - main.port
+//	script:
+//	  normalize: true
+//	bundle:
+//	  - path: third_party/{{.Os}}_{{.Arch}}/jq
+//	processes:
+//	  order-api:
+//	    module: services/order
+//	    type: GENERAL
+//	    package: binary
+//	    binaries:
+//	      - api
+//	      - migrate
+type ProjectConfig struct {
+	Process    ProcessConfig    `yaml:"process,omitempty"`
+	Resource   ResourceConfig   `yaml:"resource,omitempty"`
+	Profile    ProfileConfig    `yaml:"profile,omitempty"`
+	Template   TemplateConfig   `yaml:"template,omitempty"`
+	Properties PropertiesConfig `yaml:"properties,omitempty"`
+	Script     ScriptConfig     `yaml:"script,omitempty"`
+	Bundle     []BundleItem     `yaml:"bundle,omitempty"`
+	// Processes 노출 프로세스 이름별 설정. 하나의 저장소에서 여러 far 를 만들 때 사용한다
+	Processes map[string]ProcessEntry `yaml:"processes,omitempty"`
+}
+
+type ProcessEntry struct {
+	// Binaries far 에 포함할 바이너리(main 패키지 디렉토리명) 목록. 지정하지 않으면 모든 바이너리를 포함한다
+	Binaries []string `yaml:"binaries,omitempty"`
+	// Module go.work workspace 에서 프로세스를 빌드할 모듈 (go.work 디렉토리로부터의 상대경로 또는 모듈 경로)
+	Module string `yaml:"module,omitempty"`
+	// Type 프로세스 타입. 지정하면 process.type 설정보다 우선한다
+	Type string `yaml:"type,omitempty"`
+	// Package 패키징 모드. 지정하면 process.package 설정보다 우선한다
+	Package string `yaml:"package,omitempty"`
+}
+
+type PropertiesConfig struct {
+	// Merge profile 의 properties 파일을 기본 파일에 키 단위로 병합할지 여부 (false 이면 파일을 교체한다)
+	Merge bool `yaml:"merge,omitempty"`
+	// Required application.properties 에 반드시 존재해야 하는 키 목록
+	Required []string `yaml:"required,omitempty"`
+	// SkipValidation properties 파일 검사를 하지 않는다
+	SkipValidation bool `yaml:"skip_validation,omitempty"`
+}
+
+type TemplateConfig struct {
+	// Files *.tmpl 외에 변수 치환을 적용할 리소스 패턴 (far 내부 경로 기준)
+	Files []string `yaml:"files,omitempty"`
+	// Vars 템플릿에서 사용할 사용자 정의 변수
+	Vars map[string]string `yaml:"vars,omitempty"`
+}
+
+type ProfileConfig struct {
+	// FarName far 파일명에 profile 을 포함할지 여부 (<process>-<profile>.far)
+	FarName bool `yaml:"far_name,omitempty"`
+}
+
+type ResourceConfig struct {
+	// Suffix 수집할 리소스 확장자 또는 glob 패턴 목록. 지정하면 기본 목록을 대체한다
+	Suffix []string `yaml:"suffix,omitempty"`
+	// ExtraSuffix 기본(또는 Suffix) 목록에 추가할 확장자 또는 glob 패턴 목록
+	ExtraSuffix []string `yaml:"extra_suffix,omitempty"`
+	// Layout far 내부의 리소스 배치 방식. flatten(기본) 또는 preserve
+	Layout string `yaml:"layout,omitempty"`
+	// Root preserve 모드에서 상대경로의 기준이 되는 디렉토리 (프로젝트 base 디렉토리 기준)
+	Root string `yaml:"root,omitempty"`
+	// Symlink resources 디렉토리의 심볼릭 링크 처리 방식. follow(기본) 또는 preserve
+	Symlink string   `yaml:"symlink,omitempty"`
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+	// SkipValidation xml/json/yaml 리소스 형식 검사를 하지 않는다
+	SkipValidation bool `yaml:"skip_validation,omitempty"`
+	// Platform 플랫폼(<os>_<arch>)별 리소스 디렉토리 (프로젝트 base 디렉토리 기준)
+	Platform map[string]string `yaml:"platform,omitempty"`
+}
+
+type ProcessConfig struct {
+	// Type 프로세스 타입 (GENERAL, USER_INTERACTIVE). 지정하지 않으면 <process>.ui.xml 존재 여부로 결정한다
+	Type string `yaml:"type,omitempty"`
+	// Package 패키징 모드. binary 또는 script(go 바이너리 없이 스크립트와 리소스만 패키징).
+	// 지정하지 않으면 main 패키지가 없는 경우 script 로 동작한다
+	Package string `yaml:"package,omitempty"`
+}
+
+type ScriptConfig struct {
+	// Normalize 패키징되는 .sh 파일의 CRLF 라인끝과 UTF-8 BOM 을 정리한다
+	Normalize bool `yaml:"normalize,omitempty"`
+	// SkipCheck .sh 파일 검사를 하지 않는다
+	SkipCheck bool `yaml:"skip_check,omitempty"`
+}
+
+// loadProjectConfig 프로젝트 base 디렉토리의 .gofar.yaml 파일을 로드한다
+// 파일이 없는 경우 기본 설정을 사용한다
+func loadProjectConfig(baseDir string) (ProjectConfig, error) {
+	config := ProjectConfig{}
+
+	yamlFilePath := filepath.Join(baseDir, ProjectConfigFile)
+	data, err := os.ReadFile(yamlFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return config, fmt.Errorf("fail to read project config : %s", err.Error())
+	}
+
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return config, fmt.Errorf("invalid project config %s : %s", yamlFilePath, err.Error())
+	}
+
+	return config, nil
+}
+
+// applyProcessEntry procName 의 processes 설정에 지정된 type, package 를 process 설정에 반영한다
+func (c *ProjectConfig) applyProcessEntry(procName string) {
+	entry, ok := c.Processes[procName]
+	if !ok {
+		return
+	}
+
+	if len(entry.Type) > 0 {
+		c.Process.Type = entry.Type
+	}
+	if len(entry.Package) > 0 {
+		c.Process.Package = entry.Package
+	}
+}
@@ -0,0 +1,302 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 7:30
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	propertiesSuffix  = ".properties"
+	processConfigFile = "application.properties"
+)
+
+type propertyEntry struct {
+	Key   string
+	Value string
+	// Line 1 부터 시작하는 라인번호. startIdx, endIdx 는 lines 의 인덱스 범위(continuation 포함)
+	Line     int
+	startIdx int
+	endIdx   int
+}
+
+// Properties fatima 프로세스 설정(properties) 파일
+type Properties struct {
+	Name    string
+	lines   []string
+	entries []propertyEntry
+	index   map[string]int
+}
+
+// parseProperties properties 파일을 파싱하고 중복 키, 잘못된 라인, UTF-8 이 아닌 라인을 "name:line : message" 형태의 에러 목록으로 리턴한다
+func parseProperties(name string, data []byte) (*Properties, []string) {
+	p := &Properties{Name: name, entries: make([]propertyEntry, 0), index: make(map[string]int)}
+	errList := make([]string, 0)
+
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	if len(content) > 0 {
+		p.lines = strings.Split(content, "\n")
+	}
+
+	for i := 0; i < len(p.lines); i++ {
+		lineNo := i + 1
+		line := p.lines[i]
+		if !utf8.ValidString(line) {
+			errList = append(errList, fmt.Sprintf("%s:%d : invalid UTF-8 content", name, lineNo))
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) == 0 || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+
+		// '\' 로 끝나는 라인은 다음 라인과 이어진다
+		start := i
+		logical := trimmed
+		for strings.HasSuffix(logical, "\\") && i+1 < len(p.lines) {
+			i++
+			logical = strings.TrimSuffix(logical, "\\") + strings.TrimSpace(p.lines[i])
+		}
+
+		// java properties 와 동일하게 '=', ':' 또는 공백으로 키와 값을 구분한다 (key = value, key value)
+		sep := strings.IndexAny(logical, "=: \t\f")
+		if sep < 0 {
+			errList = append(errList, fmt.Sprintf("%s:%d : malformed line (key=value expected) : %s", name, lineNo, trimmed))
+			continue
+		}
+
+		key := logical[:sep]
+		if len(key) == 0 {
+			errList = append(errList, fmt.Sprintf("%s:%d : empty key", name, lineNo))
+			continue
+		}
+
+		if prev, ok := p.index[key]; ok {
+			errList = append(errList, fmt.Sprintf("%s:%d : duplicate key %s (first defined at line %d)",
+				name, lineNo, key, p.entries[prev].Line))
+			continue
+		}
+
+		value := strings.TrimLeft(logical[sep:], " \t\f")
+		if strings.HasPrefix(value, "=") || strings.HasPrefix(value, ":") {
+			value = value[1:]
+		}
+
+		p.index[key] = len(p.entries)
+		p.entries = append(p.entries, propertyEntry{
+			Key:      key,
+			Value:    strings.TrimSpace(value),
+			Line:     lineNo,
+			startIdx: start,
+			endIdx:   i,
+		})
+	}
+
+	return p, errList
+}
+
+func (p *Properties) Get(key string) (string, bool) {
+	i, ok := p.index[key]
+	if !ok {
+		return "", false
+	}
+	return p.entries[i].Value, true
+}
+
+// Merge override 의 값들을 덮어쓴다. 기존 키는 원래 위치에서 값을 교체하고 새로운 키는 뒤에 추가한다
+func (p *Properties) Merge(override *Properties) {
+	replaced := make(map[int]string)
+	appended := make([]string, 0)
+	for _, entry := range override.entries {
+		line := fmt.Sprintf("%s=%s", entry.Key, entry.Value)
+		if i, ok := p.index[entry.Key]; ok {
+			replaced[i] = line
+			p.entries[i].Value = entry.Value
+			continue
+		}
+		appended = append(appended, line)
+	}
+
+	lines := make([]string, 0, len(p.lines)+len(appended))
+	entryAt := make(map[int]int)
+	for i, entry := range p.entries {
+		entryAt[entry.startIdx] = i
+	}
+	for i := 0; i < len(p.lines); i++ {
+		if ei, ok := entryAt[i]; ok {
+			if line, ok := replaced[ei]; ok {
+				lines = append(lines, line)
+				i = p.entries[ei].endIdx
+				continue
+			}
+		}
+		lines = append(lines, p.lines[i])
+	}
+	if len(appended) > 0 {
+		lines = append(lines, fmt.Sprintf("# merged from %s", override.Name))
+		lines = append(lines, appended...)
+	}
+
+	reparsed, _ := parseProperties(p.Name, []byte(strings.Join(lines, "\n")))
+	*p = *reparsed
+}
+
+func (p *Properties) Bytes() []byte {
+	if len(p.lines) == 0 {
+		return []byte{}
+	}
+	return []byte(strings.Join(p.lines, "\n") + "\n")
+}
+
+func readProperties(path, name string) (*Properties, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to read %s : %s", name, err.Error())
+	}
+	p, errList := parseProperties(name, data)
+	return p, errList, nil
+}
+
+// mergePropertiesFile overridePath 의 properties 를 targetPath 의 properties 에 병합한다
+func mergePropertiesFile(targetPath, overridePath, name string) error {
+	base, errList, err := readProperties(targetPath, name)
+	if err != nil {
+		return err
+	}
+	override, overrideErrList, err := readProperties(overridePath, overridePath)
+	if err != nil {
+		return err
+	}
+	errList = append(errList, overrideErrList...)
+	if len(errList) > 0 {
+		return fmt.Errorf("fail to merge properties\n%s", strings.Join(errList, "\n"))
+	}
+
+	fmt.Printf("merge : %s <- %s\n", name, overridePath)
+	base.Merge(override)
+	info, err := os.Stat(targetPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(targetPath, base.Bytes(), info.Mode().Perm())
+}
+
+// mergeProfileProperties profile 디렉토리의 properties 파일 중 기본 리소스에 이미 존재하는 파일은 덮어쓰지 않고 병합한다
+func (b *BuildContext) mergeProfileProperties() ([]string, error) {
+	merged := make([]string, 0)
+	profileDir := b.profileDir()
+	err := filepath.Walk(profileDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, propertiesSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(profileDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(b.workingDir, rel)
+		if CheckFileExist(target) != nil {
+			return nil
+		}
+		err = mergePropertiesFile(target, path, rel)
+		if err != nil {
+			return err
+		}
+		merged = append(merged, rel)
+		return nil
+	})
+	return merged, err
+}
+
+// validateProperties 작업 디렉토리의 properties 파일들을 검사하고, 프로젝트 설정에 지정된
+// application.properties 의 필수 키가 존재하는지 확인한다
+func (b *BuildContext) validateProperties() error {
+	if b.project.Properties.SkipValidation {
+		return nil
+	}
+
+	fmt.Printf("\n>> validating properties...\n")
+	errList := make([]string, 0)
+	var processConfig *Properties
+	err := filepath.Walk(b.workingDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(b.workingDir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if skipPlatformFile(rel, b.platformResources) {
+			return nil
+		}
+		if !strings.HasSuffix(path, propertiesSuffix) {
+			return nil
+		}
+
+		p, fileErrList, err := readProperties(path, rel)
+		if err != nil {
+			return err
+		}
+		errList = append(errList, fileErrList...)
+		if rel == processConfigFile {
+			processConfig = p
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	required := b.project.Properties.Required
+	if len(required) > 0 {
+		if processConfig == nil {
+			errList = append(errList, fmt.Sprintf("%s : not found (required keys %s)", processConfigFile, strings.Join(required, ",")))
+		} else {
+			missing := make([]string, 0)
+			for _, key := range required {
+				if _, ok := processConfig.Get(key); !ok {
+					missing = append(missing, key)
+				}
+			}
+			sort.Strings(missing)
+			if len(missing) > 0 {
+				errList = append(errList, fmt.Sprintf("%s : missing required keys %s", processConfigFile, strings.Join(missing, ",")))
+			}
+		}
+	}
+
+	if len(errList) > 0 {
+		return fmt.Errorf("invalid properties\n%s", strings.Join(errList, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 7:55
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	data := "# comment\nmain.port=8080\nmain.host : localhost\nbrokenline\nmain.port=9090\nlong=a,\\\n  b\nbad=\xff\xfe\n"
+	p, errList := parseProperties("application.properties", []byte(data))
+	assert.Equal(t, []string{
+		"application.properties:4 : malformed line (key=value expected) : brokenline",
+		"application.properties:5 : duplicate key main.port (first defined at line 2)",
+		"application.properties:8 : invalid UTF-8 content",
+	}, errList)
+
+	value, ok := p.Get("main.host")
+	assert.True(t, ok)
+	assert.Equal(t, "localhost", value)
+	value, _ = p.Get("long")
+	assert.Equal(t, "a,b", value)
+
+	p, errList = parseProperties("application.properties", []byte("main.host localhost\nmain.port\t = 8080\nmain.url=http://a:80\n"))
+	assert.Empty(t, errList)
+	value, _ = p.Get("main.host")
+	assert.Equal(t, "localhost", value)
+	value, _ = p.Get("main.port")
+	assert.Equal(t, "8080", value)
+	value, _ = p.Get("main.url")
+	assert.Equal(t, "http://a:80", value)
+}
+
+func TestMergeProperties(t *testing.T) {
+	base, errList := parseProperties("application.properties", []byte("# base\nmain.port=8080\nlong=a,\\\n  b\nlog.level=debug\n"))
+	assert.Empty(t, errList)
+	override, errList := parseProperties("prod/application.properties", []byte("log.level=info\nlong=c\nnew.key=1\n"))
+	assert.Empty(t, errList)
+
+	base.Merge(override)
+	assert.Equal(t, "# base\nmain.port=8080\nlong=c\nlog.level=info\n# merged from prod/application.properties\nnew.key=1\n", string(base.Bytes()))
+}
@@ -0,0 +1,428 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 3:20
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var repackUsage = `usage: %s repack [option] far_file
+
+repack an existing far with new resource files.
+binaries under platform/ are kept untouched.
+
+positional arguments:
+  far_file              base far artifact
+
+optional arguments:
+  --resources dir       resource directory overlaid on the base far (required)
+  -o file               output far path (default : $GOPATH/far/<process>/<process>.far)
+  -release              release build (fail when secrets are found in resources)
+
+secret suspects can be allowed by a .farsecret-allowlist file in the resource directory.
+`
+
+// Repack repack 서브 커맨드를 처리한다
+func Repack(args []string) error {
+	fs := flag.NewFlagSet("repack", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf(repackUsage, os.Args[0])
+	}
+
+	var resourceDir, outputPath string
+	var release bool
+	fs.StringVar(&resourceDir, "resources", "", "resource directory")
+	fs.StringVar(&outputPath, "o", "", "output far path")
+	fs.BoolVar(&release, "release", false, "release build")
+	_ = fs.Parse(sortFlagArgs(fs, args))
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return fmt.Errorf("far file is not specified")
+	}
+	baseFar := fs.Arg(0)
+
+	if len(resourceDir) == 0 {
+		fs.Usage()
+		return fmt.Errorf("resource directory is not specified")
+	}
+
+	ctx, err := NewRepackContext(baseFar, resourceDir, outputPath)
+	if err != nil {
+		return err
+	}
+	ctx.Release = release
+	return ctx.Repack()
+}
+
+// sortFlagArgs far 파일 뒤에 옵션이 오는 경우(repack my.far --resources dir)도 한번에 Parse 할 수 있도록
+// 옵션을 위치 인자 앞으로 옮긴다
+func sortFlagArgs(fs *flag.FlagSet, args []string) []string {
+	flags := make([]string, 0, len(args))
+	positional := make([]string, 0)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			positional = append(positional, arg)
+			continue
+		}
+
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			continue
+		}
+		if i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return append(append(flags, "--"), positional...)
+}
+
+// RepackContext 기존 far 의 바이너리는 그대로 두고 리소스만 교체하여 새로운 far 를 만든다
+type RepackContext struct {
+	BaseFarPath string
+	ResourceDir string
+	OutputPath  string
+	Release     bool
+	baseHash    string
+	workingDir  string
+}
+
+func NewRepackContext(baseFar, resourceDir, outputPath string) (*RepackContext, error) {
+	ctx := &RepackContext{}
+
+	var err error
+	ctx.BaseFarPath, err = filepath.Abs(baseFar)
+	if err != nil {
+		return nil, fmt.Errorf("invalid far path %s : %s", baseFar, err.Error())
+	}
+	err = CheckFileExist(ctx.BaseFarPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.ResourceDir, err = filepath.Abs(resourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource dir %s : %s", resourceDir, err.Error())
+	}
+	err = CheckDirExist(ctx.ResourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(outputPath) > 0 {
+		ctx.OutputPath, err = filepath.Abs(outputPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output path %s : %s", outputPath, err.Error())
+		}
+	}
+
+	return ctx, nil
+}
+
+func (r *RepackContext) Repack() error {
+	var err error
+	r.workingDir, err = os.MkdirTemp("", "gofar_repack")
+	if err != nil {
+		return fmt.Errorf("fail to create tmp dir : %s", err.Error())
+	}
+
+	fmt.Printf("working directory : %s\n", r.workingDir)
+	defer func() {
+		_ = os.RemoveAll(r.workingDir)
+	}()
+
+	r.baseHash, err = FileSha256(r.BaseFarPath)
+	if err != nil {
+		return fmt.Errorf("fail to hash %s : %s", r.BaseFarPath, err.Error())
+	}
+
+	fmt.Printf("\n>> extracting %s\n", r.BaseFarPath)
+	err = UnzipArtifact(r.BaseFarPath, r.workingDir)
+	if err != nil {
+		return fmt.Errorf("fail to extract far : %s", err.Error())
+	}
+
+	baseDeployment, err := readDeployment(r.workingDir)
+	if err != nil {
+		return err
+	}
+
+	procName, ok := baseDeployment["process"].(string)
+	if !ok || len(procName) == 0 {
+		return fmt.Errorf("invalid %s : process name not found", deploymentFilename)
+	}
+
+	err = r.overlayResource()
+	if err != nil {
+		return err
+	}
+
+	m := make(map[string]interface{})
+	for k, v := range baseDeployment {
+		m[k] = v
+	}
+	// 프로세스 타입은 base far 의 값(또는 값이 없는 상태)을 유지하고, 리소스로 <process>.ui.xml 이 추가된 경우에만 변경한다
+	if CheckFileExist(filepath.Join(r.ResourceDir, fmt.Sprintf("%s.ui.xml", procName))) == nil {
+		m["process_type"] = procTypeUI
+	}
+	m["build"] = newBuildInfo()
+	m["base"] = map[string]interface{}{
+		"far":    filepath.Base(r.BaseFarPath),
+		"sha256": r.baseHash,
+		"build":  baseDeployment["build"],
+	}
+	err = writeDeployment(r.workingDir, m)
+	if err != nil {
+		return err
+	}
+
+	allow, err := loadSecretAllowlist(filepath.Join(r.ResourceDir, secretAllowlistFilename))
+	if err != nil {
+		return err
+	}
+	// platform 디렉토리는 repack 으로 교체할 수 없으므로 검사하지 않는다
+	err = checkSecrets(r.workingDir, allow, nil, r.Release)
+	if err != nil {
+		return err
+	}
+
+	ctx := &BuildContext{ExposeProcessName: procName, workingDir: r.workingDir}
+	if len(r.OutputPath) > 0 {
+		ctx.farPath = r.OutputPath
+	}
+	err = ctx.compress()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSUCCESS to repackaging...\nBase :: %s (sha256 %s)\nArtifact :: %s\n\n",
+		r.BaseFarPath, r.baseHash, ctx.farPath)
+	return nil
+}
+
+// overlayResource 리소스 디렉토리의 파일들을 작업 디렉토리에 덮어쓴다
+// platform 디렉토리와 deployment.json 은 교체할 수 없다
+func (r *RepackContext) overlayResource() error {
+	fmt.Printf("\n>> overlaying resources from %s\n", r.ResourceDir)
+
+	count := 0
+	err := filepath.Walk(r.ResourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(r.ResourceDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+
+		if rel == PlatformDirName || strings.HasPrefix(rel, PlatformDirName+string(os.PathSeparator)) {
+			return fmt.Errorf("resource %s : platform directory cannot be replaced by repack", rel)
+		}
+		if rel == deploymentFilename {
+			return fmt.Errorf("resource %s : %s cannot be replaced by repack", rel, deploymentFilename)
+		}
+		// secret allowlist 는 repack 시 검사에만 사용하고 far 에는 포함하지 않는다
+		if rel == secretAllowlistFilename {
+			return nil
+		}
+
+		// base far 의 심볼릭 링크를 통해 작업 디렉토리 밖에 기록하지 않도록 한다
+		err = checkSymlinkFreePath(r.workingDir, rel)
+		if err != nil {
+			return fmt.Errorf("resource %s : %s", rel, err.Error())
+		}
+
+		target := filepath.Join(r.workingDir, rel)
+		if info.IsDir() {
+			return EnsureDirectory(target)
+		}
+
+		err = CopyFile(path, target)
+		if err != nil {
+			return fmt.Errorf("fail to copy resource %s : %s", path, err.Error())
+		}
+		if strings.HasSuffix(target, ".sh") {
+			_ = os.Chmod(target, 0755)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("total %d resource files overlaid...\n", count)
+	return nil
+}
+
+// checkSymlinkFreePath baseDir 로부터 rel 까지의 경로 중 이미 존재하는 요소가 심볼릭 링크이면 에러를 리턴한다
+func checkSymlinkFreePath(baseDir, rel string) error {
+	path := baseDir
+	for _, element := range strings.Split(rel, string(os.PathSeparator)) {
+		path = filepath.Join(path, element)
+		info, err := os.Lstat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			rel, _ := filepath.Rel(baseDir, path)
+			return fmt.Errorf("cannot write through symlink %s", rel)
+		}
+	}
+	return nil
+}
+
+func readDeployment(workingDir string) (map[string]interface{}, error) {
+	depfile := filepath.Join(workingDir, deploymentFilename)
+	data, err := os.ReadFile(depfile)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read %s : %s", deploymentFilename, err.Error())
+	}
+
+	m := make(map[string]interface{})
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s : %s", deploymentFilename, err.Error())
+	}
+	return m, nil
+}
+
+// UnzipArtifact far 파일을 targetDir 에 압축해제한다
+func UnzipArtifact(artifactFile, targetDir string) error {
+	zr, err := zip.OpenReader(artifactFile)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		// far 의 엔트리는 /platform/... 와 같이 '/' 로 시작한다
+		name := strings.TrimLeft(filepath.FromSlash(f.Name), string(os.PathSeparator))
+		if len(name) == 0 {
+			continue
+		}
+
+		target := filepath.Join(targetDir, name)
+		if !strings.HasPrefix(target, filepath.Clean(targetDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal entry path : %s", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			err = EnsureDirectory(target)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = EnsureDirectory(filepath.Dir(target))
+		if err != nil {
+			return err
+		}
+		err = extractZipEntry(f, targetDir, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractZipEntry 엔트리를 target 에 기록한다
+// 심볼릭 링크는 targetDir 내부를 가리키는 상대경로인 경우에만 허용한다
+func extractZipEntry(f *zip.File, targetDir, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if f.Mode()&os.ModeSymlink != 0 {
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return err
+		}
+		link := string(data)
+		if filepath.IsAbs(link) || !isSubPath(targetDir, filepath.Join(filepath.Dir(target), link)) {
+			return fmt.Errorf("illegal symlink entry : %s -> %s", f.Name, link)
+		}
+		return os.Symlink(link, target)
+	}
+
+	mode := f.Mode().Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, rc)
+	return err
+}
+
+func FileSha256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
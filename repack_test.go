@@ -0,0 +1,189 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 3:40
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepack(t *testing.T) {
+	baseDir := t.TempDir()
+	binDir := filepath.Join(baseDir, PlatformDirName, "linux_amd64")
+	assert.Nil(t, os.MkdirAll(binDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(binDir, "hello"), []byte("binary"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(baseDir, "application.properties"), []byte("a=1\n"), 0644))
+	assert.Nil(t, writeDeployment(baseDir, map[string]interface{}{"process": "hello", "process_type": procTypeGeneral}))
+
+	baseFar := filepath.Join(t.TempDir(), "hello.far")
+	assert.Nil(t, ZipArtifact(baseDir, baseFar))
+
+	resourceDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(resourceDir, "application.properties"), []byte("a=2\n"), 0644))
+
+	outFar := filepath.Join(t.TempDir(), "hello.far")
+	ctx, err := NewRepackContext(baseFar, resourceDir, outFar)
+	assert.Nil(t, err)
+	assert.Nil(t, ctx.Repack())
+
+	extractDir := t.TempDir()
+	assert.Nil(t, UnzipArtifact(outFar, extractDir))
+
+	data, err := os.ReadFile(filepath.Join(extractDir, "application.properties"))
+	assert.Nil(t, err)
+	assert.Equal(t, "a=2\n", string(data))
+
+	data, err = os.ReadFile(filepath.Join(extractDir, PlatformDirName, "linux_amd64", "hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, "binary", string(data))
+
+	m, err := readDeployment(extractDir)
+	assert.Nil(t, err)
+	base, ok := m["base"].(map[string]interface{})
+	assert.True(t, ok, "base section not found")
+	hash, _ := FileSha256(baseFar)
+	assert.Equal(t, hash, base["sha256"])
+}
+
+func TestRepackKeepProcessType(t *testing.T) {
+	testCases := []struct {
+		name       string
+		baseType   interface{}
+		overlayUI  bool
+		expectType interface{}
+	}{
+		{name: "explicit ui type", baseType: procTypeUI, expectType: procTypeUI},
+		{name: "general", baseType: procTypeGeneral, expectType: procTypeGeneral},
+		{name: "script package without type", baseType: nil, expectType: nil},
+		{name: "overlay adds ui xml", baseType: procTypeGeneral, overlayUI: true, expectType: procTypeUI},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			deployment := map[string]interface{}{"process": "hello"}
+			if tc.baseType != nil {
+				deployment["process_type"] = tc.baseType
+			}
+			assert.Nil(t, writeDeployment(baseDir, deployment))
+			baseFar := filepath.Join(t.TempDir(), "hello.far")
+			assert.Nil(t, ZipArtifact(baseDir, baseFar))
+
+			resourceDir := t.TempDir()
+			assert.Nil(t, os.WriteFile(filepath.Join(resourceDir, "application.properties"), []byte("a=2\n"), 0644))
+			if tc.overlayUI {
+				assert.Nil(t, os.WriteFile(filepath.Join(resourceDir, "hello.ui.xml"), []byte("<process/>\n"), 0644))
+			}
+
+			outFar := filepath.Join(t.TempDir(), "hello.far")
+			ctx, err := NewRepackContext(baseFar, resourceDir, outFar)
+			assert.Nil(t, err)
+			assert.Nil(t, ctx.Repack())
+
+			extractDir := t.TempDir()
+			assert.Nil(t, UnzipArtifact(outFar, extractDir))
+			m, err := readDeployment(extractDir)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectType, m["process_type"])
+		})
+	}
+}
+
+func TestRepackRejectPlatformResource(t *testing.T) {
+	resourceDir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(resourceDir, PlatformDirName), 0755))
+
+	ctx := &RepackContext{ResourceDir: resourceDir, workingDir: t.TempDir()}
+	assert.NotNil(t, ctx.overlayResource())
+}
+
+func writeSymlinkFar(t *testing.T, link string) string {
+	farPath := filepath.Join(t.TempDir(), "hello.far")
+	out, err := os.Create(farPath)
+	assert.Nil(t, err)
+	zw := zip.NewWriter(out)
+	header := &zip.FileHeader{Name: "/conf"}
+	header.SetMode(os.ModeSymlink | 0777)
+	w, err := zw.CreateHeader(header)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte(link))
+	assert.Nil(t, err)
+	assert.Nil(t, zw.Close())
+	assert.Nil(t, out.Close())
+	return farPath
+}
+
+func TestUnzipArtifactSymlink(t *testing.T) {
+	assert.Nil(t, UnzipArtifact(writeSymlinkFar(t, "platform/linux_amd64"), t.TempDir()))
+
+	err := UnzipArtifact(writeSymlinkFar(t, t.TempDir()), t.TempDir())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "illegal symlink entry")
+
+	assert.NotNil(t, UnzipArtifact(writeSymlinkFar(t, "../outside"), t.TempDir()))
+}
+
+func TestRepackRejectSymlinkOverlay(t *testing.T) {
+	outsideDir := t.TempDir()
+	workingDir := t.TempDir()
+	assert.Nil(t, os.Symlink(outsideDir, filepath.Join(workingDir, "conf")))
+
+	resourceDir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(resourceDir, "conf"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(resourceDir, "conf", "x.properties"), []byte("a=1\n"), 0644))
+
+	ctx := &RepackContext{ResourceDir: resourceDir, workingDir: workingDir}
+	err := ctx.overlayResource()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot write through symlink conf")
+	assert.NotNil(t, CheckFileExist(filepath.Join(outsideDir, "x.properties")))
+}
+
+func TestSortFlagArgs(t *testing.T) {
+	fs := flag.NewFlagSet("repack", flag.ContinueOnError)
+	var resourceDir, outputPath string
+	var release bool
+	fs.StringVar(&resourceDir, "resources", "", "")
+	fs.StringVar(&outputPath, "o", "", "")
+	fs.BoolVar(&release, "release", false, "")
+
+	args := sortFlagArgs(fs, []string{"my.far", "--resources", "dir", "-release", "-o=out.far"})
+	assert.Equal(t, []string{"--resources", "dir", "-release", "-o=out.far", "--", "my.far"}, args)
+
+	assert.Nil(t, fs.Parse(args))
+	assert.Equal(t, "dir", resourceDir)
+	assert.Equal(t, "out.far", outputPath)
+	assert.True(t, release)
+	assert.Equal(t, []string{"my.far"}, fs.Args())
+}
+
+func TestRepackSecretAllowlist(t *testing.T) {
+	resourceDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(resourceDir, secretAllowlistFilename), []byte("*\n"), 0644))
+
+	ctx := &RepackContext{ResourceDir: resourceDir, workingDir: t.TempDir()}
+	assert.Nil(t, ctx.overlayResource())
+	assert.NotNil(t, CheckFileExist(filepath.Join(ctx.workingDir, secretAllowlistFilename)))
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 5:02
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	resourceLayoutFlatten  = "flatten"
+	resourceLayoutPreserve = "preserve"
+
+	resourceSymlinkFollow   = "follow"
+	resourceSymlinkPreserve = "preserve"
+)
+
+// ResourceFile 리소스 원본 파일과 far 내부에서의 상대 경로
+type ResourceFile struct {
+	Source string
+	Target string
+}
+
+// resolveResourceTargets 프로젝트에서 찾은 리소스 파일들의 far 내부 경로를 결정한다
+// flatten 모드(기본)에서는 파일명만 사용하고, preserve 모드에서는 root 디렉토리 기준 상대경로를 유지한다
+// (root 밖의 파일은 프로젝트 base 디렉토리 기준 상대경로를 사용한다)
+// 서로 다른 원본이 같은 경로로 패키징되는 경우 두 원본 경로를 모두 포함한 에러를 리턴한다
+func resolveResourceTargets(baseDir string, fileList []string, config ResourceConfig) ([]ResourceFile, error) {
+	layout := config.Layout
+	if len(layout) == 0 {
+		layout = resourceLayoutFlatten
+	}
+	if layout != resourceLayoutFlatten && layout != resourceLayoutPreserve {
+		return nil, fmt.Errorf("invalid resource layout %s (flatten or preserve)", layout)
+	}
+
+	rootDir := filepath.Join(baseDir, config.Root)
+
+	resourceList := make([]ResourceFile, 0, len(fileList))
+	sourceMap := make(map[string]string)
+	collisionList := make([]string, 0)
+	for _, source := range fileList {
+		target := filepath.Base(source)
+		if layout == resourceLayoutPreserve {
+			rel, err := filepath.Rel(rootDir, source)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				rel, err = filepath.Rel(baseDir, source)
+				if err != nil {
+					return nil, fmt.Errorf("fail to resolve resource path %s : %s", source, err.Error())
+				}
+			}
+			target = rel
+		}
+
+		if prev, ok := sourceMap[target]; ok {
+			collisionList = append(collisionList, fmt.Sprintf("%s : %s, %s", target, prev, source))
+			continue
+		}
+		sourceMap[target] = source
+		resourceList = append(resourceList, ResourceFile{Source: source, Target: target})
+	}
+
+	if len(collisionList) > 0 {
+		sort.Strings(collisionList)
+		return nil, fmt.Errorf("resource name collision (use resource layout preserve or exclude one of them)\n%s",
+			strings.Join(collisionList, "\n"))
+	}
+
+	return resourceList, nil
+}
+
+// isReservedResourceDir resources 디렉토리 기준 상대경로가 profiles 또는 platform 디렉토리인지 여부
+// 두 디렉토리는 기본 리소스로 복사하지 않는다
+func isReservedResourceDir(relPath string) bool {
+	return relPath == profilesDirname || relPath == PlatformDirName
+}
+
+// followSymlink 프로젝트 설정의 resource.symlink 값에 따라 심볼릭 링크를 따라갈지 여부를 구한다
+func (b *BuildContext) followSymlink() (bool, error) {
+	symlink := b.project.Resource.Symlink
+	if len(symlink) > 0 && symlink != resourceSymlinkFollow && symlink != resourceSymlinkPreserve {
+		return false, fmt.Errorf("invalid resource symlink %s (follow or preserve)", symlink)
+	}
+	return symlink != resourceSymlinkPreserve, nil
+}
+
+// platformResourceDirs 빌드 대상 플랫폼별 리소스 디렉토리 목록을 구한다
+// resources/platform/<os>_<arch> 디렉토리와 프로젝트 설정의 resource.platform 매핑(프로젝트 base 디렉토리 기준)을 사용한다
+// <os>_<arch> 리소스는 해당 플랫폼의 모든 variant(<os>_<arch>_<variant>)에도 배치된다
+func (b *BuildContext) platformResourceDirs() (map[string][]string, error) {
+	dirs := make(map[string][]string)
+	targets := make(map[string][]string)
+	for _, platform := range buildPlatformList.GetBuildPlatforms() {
+		name := platform.getPlatformDirectory()
+		targets[name] = append(targets[name], name)
+		if len(platform.Variant) > 0 {
+			base := PlatformItem{Os: platform.Os, Arch: platform.Arch}.getPlatformDirectory()
+			targets[base] = append(targets[base], name)
+		}
+	}
+
+	baseDir := filepath.Join(b.ResourceDir, PlatformDirName)
+	if len(b.ResourceDir) > 0 && CheckDirExist(baseDir) == nil {
+		for _, dir := range FindSubDirectories(baseDir) {
+			name := filepath.Base(dir)
+			if _, ok := targets[name]; !ok {
+				fmt.Fprintf(os.Stderr, "warning : %s is not a build target platform. skipped\n", dir)
+				continue
+			}
+			for _, target := range targets[name] {
+				dirs[target] = append(dirs[target], dir)
+			}
+		}
+	}
+
+	for name, rel := range b.project.Resource.Platform {
+		dir := filepath.Join(b.ProjectBaseDir, rel)
+		err := CheckDirExist(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid platform resource %s : %s", name, err.Error())
+		}
+		if _, ok := targets[name]; !ok {
+			fmt.Fprintf(os.Stderr, "warning : %s is not a build target platform. skipped\n", name)
+			continue
+		}
+		for _, target := range targets[name] {
+			dirs[target] = append(dirs[target], dir)
+		}
+	}
+
+	return dirs, nil
+}
+
+// loadPlatformResources 플랫폼별 리소스를 far 의 platform/<os>_<arch> 디렉토리에 바이너리와 함께 배치한다
+// 빌드된 바이너리와 이름이 같은 파일은 허용하지 않는다
+func (b *BuildContext) loadPlatformResources() error {
+	dirs, err := b.platformResourceDirs()
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	followSymlink, err := b.followSymlink()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n>> copying platform resources...\n")
+	if b.platformResources == nil {
+		b.platformResources = make(map[string]struct{})
+	}
+	total := 0
+	for name, dirList := range dirs {
+		targetDir := filepath.Join(b.workingDir, PlatformDirName, name)
+		for _, dir := range dirList {
+			// 바이너리와 충돌하는 파일이 있는지 먼저 확인한다
+			list, err := listDirectoryFiles(dir, nil)
+			if err != nil {
+				return err
+			}
+			for _, r := range list {
+				if _, err := os.Lstat(filepath.Join(targetDir, r.Target)); err == nil {
+					return fmt.Errorf("platform resource %s conflicts with %s", r.Source,
+						filepath.Join(PlatformDirName, name, r.Target))
+				}
+			}
+
+			copied, err := CopyDirectory(dir, targetDir, followSymlink, nil)
+			if err != nil {
+				return fmt.Errorf("fail to copy platform resource %s : %s", dir, err.Error())
+			}
+			for _, rel := range copied {
+				b.platformResources[filepath.Join(PlatformDirName, name, rel)] = struct{}{}
+			}
+			total += len(copied)
+		}
+	}
+
+	fmt.Printf("total %d platform resource files copied...\n", total)
+	return nil
+}
+
+// skipPlatformFile 작업 디렉토리 기준 상대경로 rel 이 platform 디렉토리 하위의 바이너리이면 true 를 리턴한다
+// platform 디렉토리 하위라도 플랫폼 리소스로 복사된 파일(platformResources)은 리소스 검사 대상이다
+func skipPlatformFile(rel string, platformResources map[string]struct{}) bool {
+	if !strings.HasPrefix(rel, PlatformDirName+string(os.PathSeparator)) {
+		return false
+	}
+	_, ok := platformResources[rel]
+	return !ok
+}
+
+// listPlatformResources 플랫폼별 리소스 파일 목록을 far 내부 경로와 함께 구한다
+func (b *BuildContext) listPlatformResources() ([]ResourceFile, error) {
+	dirs, err := b.platformResourceDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceList := make([]ResourceFile, 0)
+	for name, dirList := range dirs {
+		for _, dir := range dirList {
+			list, err := listDirectoryFiles(dir, nil)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range list {
+				r.Target = filepath.Join(PlatformDirName, name, r.Target)
+				resourceList = append(resourceList, r)
+			}
+		}
+	}
+	sort.Slice(resourceList, func(i, j int) bool {
+		return resourceList[i].Target < resourceList[j].Target
+	})
+	return resourceList, nil
+}
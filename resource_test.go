@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 5:20
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveResourceTargets(t *testing.T) {
+	baseDir := "/project"
+	fileList := []string{
+		filepath.Join(baseDir, "application.properties"),
+		filepath.Join(baseDir, "conf/a/app.yaml"),
+		filepath.Join(baseDir, "conf/b/app.yaml"),
+	}
+
+	_, err := resolveResourceTargets(baseDir, fileList, ResourceConfig{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "conf/a/app.yaml")
+	assert.Contains(t, err.Error(), "conf/b/app.yaml")
+
+	resourceList, err := resolveResourceTargets(baseDir, fileList, ResourceConfig{Layout: resourceLayoutPreserve, Root: "conf"})
+	assert.Nil(t, err)
+	targets := make([]string, 0)
+	for _, r := range resourceList {
+		targets = append(targets, r.Target)
+	}
+	assert.Equal(t, []string{"application.properties", "a/app.yaml", "b/app.yaml"}, targets)
+}
+
+func TestCopyDirectory(t *testing.T) {
+	srcDir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(srcDir, "conf", "empty"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(srcDir, ".env"), []byte("A=1"), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(srcDir, "conf", "start.sh"), []byte("#!/bin/sh"), 0755))
+	assert.Nil(t, os.Symlink("conf/start.sh", filepath.Join(srcDir, "start.sh")))
+
+	dstDir := t.TempDir()
+	copied, err := CopyDirectory(srcDir, dstDir, false, nil)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{".env", "conf/start.sh", "start.sh"}, copied)
+
+	info, err := os.Stat(filepath.Join(dstDir, ".env"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	link, err := os.Readlink(filepath.Join(dstDir, "start.sh"))
+	assert.Nil(t, err)
+	assert.Equal(t, "conf/start.sh", link)
+	assert.Nil(t, CheckDirExist(filepath.Join(dstDir, "conf", "empty")))
+
+	dstDir = t.TempDir()
+	_, err = CopyDirectory(srcDir, dstDir, true, nil)
+	assert.Nil(t, err)
+	info, err = os.Lstat(filepath.Join(dstDir, "start.sh"))
+	assert.Nil(t, err)
+	assert.True(t, info.Mode().IsRegular())
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 10:05
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	shellScriptSuffix = ".sh"
+)
+
+var utf8Bom = []byte{0xEF, 0xBB, 0xBF}
+
+// supportedShellList shebang 으로 허용하는 쉘
+var supportedShellList = []string{"sh", "bash", "dash", "ksh", "zsh"}
+
+// ShellScriptCheck .sh 파일 검사 결과
+type ShellScriptCheck struct {
+	Errors   []string
+	Warnings []string
+	// Normalized 라인끝(CRLF)과 BOM 을 정리한 내용. 정리할 내용이 없으면 nil
+	Normalized []byte
+}
+
+// checkShellScript 쉘 스크립트의 CRLF 라인끝, BOM, shebang, 실행 권한을 검사한다
+// normalize 가 true 이면 CRLF 와 BOM 은 에러 대신 정리된 내용을 Normalized 로 리턴한다
+// shebang 이 없는 경우는 경고로 보고하며, release 가 true 이면 에러로 처리한다
+func checkShellScript(name string, data []byte, mode os.FileMode, normalize, release bool) ShellScriptCheck {
+	result := ShellScriptCheck{Errors: make([]string, 0), Warnings: make([]string, 0)}
+	content := data
+
+	if bytes.HasPrefix(content, utf8Bom) {
+		if normalize {
+			content = content[len(utf8Bom):]
+		} else {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s:1 : UTF-8 BOM found", name))
+		}
+	}
+
+	if idx := bytes.Index(content, []byte("\r\n")); idx >= 0 {
+		if normalize {
+			content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+		} else {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s:%d : CRLF line ending found", name, lineOfOffset(content, int64(idx))))
+		}
+	}
+
+	// BOM 은 별도로 보고하므로 shebang 검사에서는 제외한다
+	firstLine := string(bytes.TrimPrefix(content, utf8Bom))
+	if idx := strings.IndexAny(firstLine, "\r\n"); idx >= 0 {
+		firstLine = firstLine[:idx]
+	}
+	if !strings.HasPrefix(firstLine, "#!") {
+		msg := fmt.Sprintf("%s:1 : shebang not found", name)
+		if release {
+			result.Errors = append(result.Errors, msg)
+		} else {
+			result.Warnings = append(result.Warnings, msg)
+		}
+	} else if shell := shebangShell(firstLine); !isSupportedShell(shell) {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s:1 : unsupported shebang %s", name, firstLine))
+	}
+
+	if mode.Perm()&0111 == 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s : not executable (mode %s)", name, mode.Perm().String()))
+	}
+
+	if !bytes.Equal(content, data) {
+		result.Normalized = content
+	}
+	return result
+}
+
+// shebangShell shebang 라인에서 쉘 이름을 구한다 (#!/usr/bin/env bash -> bash)
+func shebangShell(line string) string {
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	shell := filepath.Base(fields[0])
+	if shell == "env" {
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") {
+				return filepath.Base(f)
+			}
+		}
+		return ""
+	}
+	return shell
+}
+
+func isSupportedShell(shell string) bool {
+	for _, s := range supportedShellList {
+		if shell == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkShellScripts 작업 디렉토리의 .sh 파일들을 검사한다 (platform 하위의 플랫폼별 리소스 포함)
+// 프로젝트 설정의 script.normalize 가 지정되면 패키징되는 사본의 라인끝과 BOM 을 정리한다
+func (b *BuildContext) checkShellScripts() error {
+	if b.project.Script.SkipCheck {
+		return nil
+	}
+
+	fmt.Printf("\n>> checking shell scripts...\n")
+	errList := make([]string, 0)
+	err := filepath.Walk(b.workingDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(b.workingDir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if !info.Mode().IsRegular() || !strings.HasSuffix(path, shellScriptSuffix) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		result := checkShellScript(rel, data, info.Mode(), b.project.Script.Normalize, releaseBuild)
+		errList = append(errList, result.Errors...)
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "warning : %s\n", warning)
+		}
+		if result.Normalized != nil {
+			fmt.Printf("normalize : %s\n", rel)
+			return os.WriteFile(path, result.Normalized, info.Mode().Perm())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(errList) > 0 {
+		return fmt.Errorf("invalid shell script (set script.normalize to fix line endings)\n%s", strings.Join(errList, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 10:30
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckShellScript(t *testing.T) {
+	result := checkShellScript("start.sh", []byte("#!/usr/bin/env bash\necho hello\n"), 0755, false, false)
+	assert.Empty(t, result.Errors)
+	assert.Empty(t, result.Warnings)
+	assert.Nil(t, result.Normalized)
+
+	data := append(append([]byte{}, utf8Bom...), []byte("#!/bin/sh\r\necho hello\r\n")...)
+	result = checkShellScript("start.sh", data, 0644, false, false)
+	assert.Equal(t, []string{"start.sh:1 : UTF-8 BOM found", "start.sh:1 : CRLF line ending found"}, result.Errors)
+	assert.Len(t, result.Warnings, 1)
+
+	result = checkShellScript("start.sh", data, 0755, true, false)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, "#!/bin/sh\necho hello\n", string(result.Normalized))
+
+	result = checkShellScript("run.sh", []byte("echo hello\n"), 0755, false, false)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, []string{"run.sh:1 : shebang not found"}, result.Warnings)
+
+	result = checkShellScript("run.sh", []byte("echo hello\n"), 0755, false, true)
+	assert.Equal(t, []string{"run.sh:1 : shebang not found"}, result.Errors)
+
+	result = checkShellScript("run.sh", []byte("#!/usr/bin/python3\nprint(1)\n"), 0755, false, false)
+	assert.Equal(t, []string{"run.sh:1 : unsupported shebang #!/usr/bin/python3"}, result.Errors)
+}
+
+func TestCheckShellScriptsPlatformDir(t *testing.T) {
+	workingDir := t.TempDir()
+	platformDir := filepath.Join(workingDir, PlatformDirName, "linux_amd64")
+	assert.Nil(t, os.MkdirAll(platformDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(platformDir, "hello"), []byte("binary"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(platformDir, "setup.sh"), []byte("#!/bin/sh\r\necho hello\r\n"), 0755))
+
+	ctx := &BuildContext{workingDir: workingDir}
+	err := ctx.checkShellScripts()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), filepath.Join(PlatformDirName, "linux_amd64", "setup.sh"))
+
+	ctx.project.Script.Normalize = true
+	assert.Nil(t, ctx.checkShellScripts())
+	data, err := os.ReadFile(filepath.Join(platformDir, "setup.sh"))
+	assert.Nil(t, err)
+	assert.Equal(t, "#!/bin/sh\necho hello\n", string(data))
+}
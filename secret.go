@@ -0,0 +1,253 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 9:10
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	secretAllowlistFilename = ".farsecret-allowlist"
+
+	secretPrivateKey    = "private-key"
+	secretAwsAccessKey  = "aws-access-key"
+	secretAwsSecretKey  = "aws-secret-key"
+	secretPasswordValue = "password"
+
+	// 패스워드로 판단할 값의 최소 길이와 최소 엔트로피(bits per char)
+	secretMinValueLength = 8
+	secretMinEntropy     = 3.0
+)
+
+var (
+	privateKeyPattern   = regexp.MustCompile(`-----BEGIN ([A-Z0-9]+ )*PRIVATE KEY( BLOCK)?-----`)
+	awsAccessKeyPattern = regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)
+	awsSecretKeyPattern = regexp.MustCompile(`(?i)aws.{0,20}(secret|private).{0,20}[=:]\s*["']?[A-Za-z0-9/+]{40}\b`)
+	secretKeyPattern    = regexp.MustCompile(`(?i)(password|passwd|pwd|secret|token|api[_.-]?key|credential)[A-Za-z0-9_.-]*\s*[=:]\s*(.+)$`)
+	// 변수 참조, 경로, 마스킹 값 등은 secret 으로 보지 않는다
+	secretPlaceholderVal = regexp.MustCompile(`^(\$\{.*\}|<.*>|[./~].*|\*+|x+|changeme|none|null|empty|secret|password)$`)
+)
+
+// SecretFinding 리소스에서 발견된 secret 의심 항목
+type SecretFinding struct {
+	File     string
+	Line     int
+	Detector string
+}
+
+func (s SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d : %s", s.File, s.Line, s.Detector)
+}
+
+// SecretAllowlist secret 검사에서 제외할 항목
+// 각 라인은 gitignore 형식의 파일 패턴이며 "pattern:detector" 로 특정 detector 만 제외할 수 있다
+//
+//	conf/test-fixture.pem
+//	application.properties:password
+type SecretAllowlist struct {
+	all      *IgnoreMatcher
+	detector map[string]*IgnoreMatcher
+}
+
+func loadSecretAllowlist(path string) (*SecretAllowlist, error) {
+	allow := &SecretAllowlist{detector: make(map[string]*IgnoreMatcher)}
+	allow.all, _ = NewIgnoreMatcher()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return allow, nil
+		}
+		return nil, fmt.Errorf("fail to read %s : %s", path, err.Error())
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		matcher := allow.all
+		if idx := strings.LastIndex(line, ":"); idx > 0 {
+			name := line[idx+1:]
+			line = line[:idx]
+			if _, ok := allow.detector[name]; !ok {
+				allow.detector[name], _ = NewIgnoreMatcher()
+			}
+			matcher = allow.detector[name]
+		}
+
+		err = matcher.AddPatterns(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d : %s", path, lineNo, err.Error())
+		}
+	}
+	return allow, scanner.Err()
+}
+
+func (a *SecretAllowlist) Allowed(finding SecretFinding) bool {
+	if a.all.Match(finding.File, false) {
+		return true
+	}
+	if m, ok := a.detector[finding.Detector]; ok {
+		return m.Match(finding.File, false)
+	}
+	return false
+}
+
+// scanSecrets dir 하위의 리소스에서 secret 의심 항목을 찾는다
+// platform 디렉토리 하위는 platformResources 에 포함된 플랫폼 리소스만 검사하고 바이너리는 제외한다
+func scanSecrets(dir string, allow *SecretAllowlist, platformResources map[string]struct{}) ([]SecretFinding, error) {
+	findings := make([]SecretFinding, 0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || skipPlatformFile(rel, platformResources) {
+			return nil
+		}
+		if !info.Mode().IsRegular() || rel == deploymentFilename {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, finding := range scanSecretContent(rel, data) {
+			if allow == nil || !allow.Allowed(finding) {
+				findings = append(findings, finding)
+			}
+		}
+		return nil
+	})
+	return findings, err
+}
+
+func scanSecretContent(name string, data []byte) []SecretFinding {
+	findings := make([]SecretFinding, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+
+		if privateKeyPattern.MatchString(line) {
+			findings = append(findings, SecretFinding{File: name, Line: lineNo, Detector: secretPrivateKey})
+			continue
+		}
+		if awsAccessKeyPattern.MatchString(line) {
+			findings = append(findings, SecretFinding{File: name, Line: lineNo, Detector: secretAwsAccessKey})
+			continue
+		}
+		if awsSecretKeyPattern.MatchString(line) {
+			findings = append(findings, SecretFinding{File: name, Line: lineNo, Detector: secretAwsSecretKey})
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		match := secretKeyPattern.FindStringSubmatch(trimmed)
+		if match != nil && isSecretValue(match[2]) {
+			findings = append(findings, SecretFinding{File: name, Line: lineNo, Detector: secretPasswordValue})
+		}
+	}
+	return findings
+}
+
+// isSecretValue 값이 placeholder 가 아니고 충분히 길고 엔트로피가 높은 경우 secret 으로 판단한다
+func isSecretValue(value string) bool {
+	value = strings.TrimSpace(value)
+	value = strings.TrimRight(value, ",;")
+	value = strings.Trim(value, "\"'")
+	if len(value) < secretMinValueLength || secretPlaceholderVal.MatchString(strings.ToLower(value)) {
+		return false
+	}
+	return shannonEntropy(value) >= secretMinEntropy
+}
+
+func shannonEntropy(s string) float64 {
+	freq := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		freq[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range freq {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// checkSecrets 패키징될 리소스에서 secret 의심 항목을 찾는다. release 빌드인 경우 발견되면 에러를 리턴하고
+// 그렇지 않으면 경고만 출력한다
+func checkSecrets(workingDir string, allow *SecretAllowlist, platformResources map[string]struct{}, release bool) error {
+	fmt.Printf("\n>> scanning secrets...\n")
+	findings, err := scanSecrets(workingDir, allow, platformResources)
+	if err != nil {
+		return fmt.Errorf("fail to scan secrets : %s", err.Error())
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	list := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		list = append(list, finding.String())
+	}
+	msg := fmt.Sprintf("%d secret suspects found (allow them in %s if intended)\n%s",
+		len(findings), secretAllowlistFilename, strings.Join(list, "\n"))
+	if release {
+		return fmt.Errorf("%s", msg)
+	}
+
+	fmt.Fprintf(os.Stderr, "warning : %s\n", msg)
+	return nil
+}
+
+// checkResourceSecrets 프로젝트 base 디렉토리의 allowlist 를 적용하여 작업 디렉토리를 검사한다
+func (b *BuildContext) checkResourceSecrets() error {
+	allow, err := loadSecretAllowlist(filepath.Join(b.ProjectBaseDir, secretAllowlistFilename))
+	if err != nil {
+		return err
+	}
+	return checkSecrets(b.workingDir, allow, b.platformResources, releaseBuild)
+}
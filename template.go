@@ -0,0 +1,194 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 6:40
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	templateSuffix = ".tmpl"
+	templateEnvKey = "env."
+)
+
+// ${name} 형태의 변수. $${name} 은 치환하지 않고 ${name} 으로 남긴다
+var templateVarPattern = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+// renderResourceTemplates 작업 디렉토리의 템플릿 리소스에 빌드 변수를 치환한다
+// *.tmpl 파일은 치환 후 .tmpl 확장자를 제거한 파일로 저장하고, 프로젝트 설정의 template.files 패턴에
+// 매칭되는 파일은 그 자리에서 치환한다. 정의되지 않은 변수가 있으면 빌드를 실패한다
+func (b *BuildContext) renderResourceTemplates() error {
+	globs, err := NewIgnoreMatcher(b.project.Template.Files...)
+	if err != nil {
+		return fmt.Errorf("invalid template files : %s", err.Error())
+	}
+
+	templateList := make([]string, 0)
+	err = filepath.Walk(b.workingDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(b.workingDir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if skipPlatformFile(rel, b.platformResources) {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if strings.HasSuffix(info.Name(), templateSuffix) || globs.Match(rel, false) {
+			templateList = append(templateList, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(templateList) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n>> rendering resource templates...\n")
+	vars := b.buildTemplateVars()
+	errList := make([]string, 0)
+	for _, path := range templateList {
+		rel, _ := filepath.Rel(b.workingDir, path)
+		err = renderTemplateFile(path, rel, vars)
+		if err != nil {
+			errList = append(errList, err.Error())
+		}
+	}
+
+	if len(errList) > 0 {
+		return fmt.Errorf("fail to render resource template\n%s", strings.Join(errList, "\n"))
+	}
+
+	fmt.Printf("total %d resource templates rendered...\n", len(templateList))
+	return nil
+}
+
+// buildTemplateVars 템플릿에서 사용할 수 있는 빌드 변수를 구한다
+// 환경변수는 ${env.NAME} 으로 참조한다
+func (b *BuildContext) buildTemplateVars() map[string]string {
+	vars := make(map[string]string)
+	vars["gofar.version"] = version
+	vars["process.name"] = b.ExposeProcessName
+	vars["process.profile"] = b.Profile
+
+	build := b.getBuildInfo()
+	vars["build.time"] = fmt.Sprintf("%v", build["time"])
+	vars["build.user"] = fmt.Sprintf("%v", build["user"])
+
+	if b.GitSupport {
+		gitInfo := readGitInfo(b.gitBaseDir)
+		if gitInfo.Valid {
+			vars["git.repo"] = gitInfo.RepoUrl
+			vars["git.branch"] = gitInfo.BranchName
+			vars["git.commit"] = gitInfo.CommitHash
+		}
+	}
+
+	for k, v := range b.project.Template.Vars {
+		vars[k] = v
+	}
+	return vars
+}
+
+func renderTemplateFile(path, rel string, vars map[string]string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%s : %s", rel, err.Error())
+	}
+
+	rendered, undefinedList := renderTemplate(string(data), vars)
+	if len(undefinedList) > 0 {
+		for i, undefined := range undefinedList {
+			undefinedList[i] = fmt.Sprintf("%s:%s", rel, undefined)
+		}
+		return fmt.Errorf("%s", strings.Join(undefinedList, "\n"))
+	}
+
+	target := path
+	if strings.HasSuffix(path, templateSuffix) {
+		target = strings.TrimSuffix(path, templateSuffix)
+		if _, err := os.Lstat(target); err == nil {
+			return fmt.Errorf("%s : %s already exists", rel, strings.TrimSuffix(rel, templateSuffix))
+		}
+		fmt.Printf("render : %s -> %s\n", rel, strings.TrimSuffix(rel, templateSuffix))
+	} else {
+		fmt.Printf("render : %s\n", rel)
+	}
+
+	err = removeFileIfExist(target)
+	if err != nil {
+		return fmt.Errorf("%s : %s", rel, err.Error())
+	}
+	err = os.WriteFile(target, []byte(rendered), info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("%s : %s", rel, err.Error())
+	}
+
+	if target != path {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+// renderTemplate content 의 변수를 치환하고 정의되지 않은 변수 목록을 "line : ${name} undefined" 형태로 리턴한다
+func renderTemplate(content string, vars map[string]string) (string, []string) {
+	undefinedList := make([]string, 0)
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		lines[i] = templateVarPattern.ReplaceAllStringFunc(line, func(match string) string {
+			if strings.HasPrefix(match, "$$") {
+				return match[1:]
+			}
+
+			name := strings.TrimSpace(match[2 : len(match)-1])
+			if strings.HasPrefix(name, templateEnvKey) {
+				if value, ok := os.LookupEnv(name[len(templateEnvKey):]); ok {
+					return value
+				}
+			} else if value, ok := vars[name]; ok {
+				return value
+			}
+
+			undefinedList = append(undefinedList, fmt.Sprintf("%d : ${%s} undefined", i+1, name))
+			return match
+		})
+	}
+	return strings.Join(lines, ""), undefinedList
+}
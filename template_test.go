@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 7:05
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	os.Setenv("GOFAR_TEMPLATE_TEST", "from-env")
+	defer os.Unsetenv("GOFAR_TEMPLATE_TEST")
+
+	vars := map[string]string{"process.name": "hello", "git.commit": "abc"}
+	content := "name=${process.name}\ncommit=${ git.commit }\nenv=${env.GOFAR_TEMPLATE_TEST}\nshell=$${HOME}\n"
+	rendered, undefinedList := renderTemplate(content, vars)
+	assert.Empty(t, undefinedList)
+	assert.Equal(t, "name=hello\ncommit=abc\nenv=from-env\nshell=${HOME}\n", rendered)
+
+	_, undefinedList = renderTemplate("a=1\nb=${unknown}\nc=${env.GOFAR_NOT_DEFINED_ENV}\n", vars)
+	assert.Equal(t, []string{"2 : ${unknown} undefined", "3 : ${env.GOFAR_NOT_DEFINED_ENV} undefined"}, undefinedList)
+}
+
+func TestBuildTemplateVarsSharesBuildInfo(t *testing.T) {
+	ctx := &BuildContext{ExposeProcessName: "hello"}
+	ctx.buildInfo = map[string]interface{}{"time": "2026-10-19 14:00:00 KST", "user": "dave"}
+
+	vars := ctx.buildTemplateVars()
+	assert.Equal(t, "2026-10-19 14:00:00 KST", vars["build.time"])
+	assert.Equal(t, "dave", vars["build.user"])
+	assert.Equal(t, "hello", vars["process.name"])
+}
+
+func TestRenderTemplateFileConflict(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.yaml.tmpl"), []byte("name: ${process.name}\n"), 0644))
+	vars := map[string]string{"process.name": "hello"}
+
+	assert.Nil(t, renderTemplateFile(filepath.Join(dir, "app.yaml.tmpl"), "app.yaml.tmpl", vars))
+	data, err := os.ReadFile(filepath.Join(dir, "app.yaml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "name: hello\n", string(data))
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.yaml.tmpl"), []byte("name: ${process.name}\n"), 0644))
+	err = renderTemplateFile(filepath.Join(dir, "app.yaml.tmpl"), "app.yaml.tmpl", vars)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "app.yaml already exists")
+}
@@ -25,6 +25,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io"
 	"os"
 	"os/exec"
@@ -149,6 +151,30 @@ func FindDirectory(baseDir, targetDir string) (string, error) {
 	return "", fmt.Errorf("%s not found", targetDir)
 }
 
+// HasMainPackage dir 에 main 패키지 go 소스가 있는지 확인한다 (테스트 파일 제외)
+func HasMainPackage(dir string) bool {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		if f.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
 func FindSubDirectories(baseDir string) []string {
 	list := make([]string, 0)
 	files, err := os.ReadDir(baseDir)
@@ -212,6 +238,119 @@ func CopyFile(src string, dst string) error {
 	return nil
 }
 
+// CopyDirectory srcDir 하위의 모든 파일(숨김 파일 포함)을 권한을 유지하여 dstDir 로 복사하고 복사된 상대경로 목록을 리턴한다
+// followSymlink 가 true 이면 심볼릭 링크가 가리키는 파일을 복사하고, false 이면 링크 자체를 유지한다
+// skip 이 nil 이 아니면 skip 이 true 를 리턴하는 상대경로(파일 또는 디렉토리)는 복사하지 않는다
+// dstDir 에 이미 같은 이름의 파일이 있으면 덮어쓴다
+func CopyDirectory(srcDir, dstDir string, followSymlink bool, skip func(relPath string) bool) ([]string, error) {
+	copied := make([]string, 0)
+	visited := make(map[string]struct{})
+	err := copyDirectory(srcDir, dstDir, "", followSymlink, skip, visited, &copied)
+	return copied, err
+}
+
+func copyDirectory(srcDir, dstDir, rel string, followSymlink bool, skip func(string) bool, visited map[string]struct{}, copied *[]string) error {
+	realDir, err := filepath.EvalSymlinks(srcDir)
+	if err != nil {
+		return err
+	}
+	if _, ok := visited[realDir]; ok {
+		return fmt.Errorf("symbolic link loop detected : %s", srcDir)
+	}
+	visited[realDir] = struct{}{}
+	defer delete(visited, realDir)
+
+	dirInfo, err := os.Stat(srcDir)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dstDir, dirInfo.Mode().Perm()|0700)
+	if err != nil {
+		return err
+	}
+
+	files, err := os.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		srcPath := filepath.Join(srcDir, file.Name())
+		dstPath := filepath.Join(dstDir, file.Name())
+		relPath := filepath.Join(rel, file.Name())
+		if skip != nil && skip(relPath) {
+			continue
+		}
+
+		info, err := os.Lstat(srcPath)
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !followSymlink {
+				link, err := os.Readlink(srcPath)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("link : %s -> %s\n", relPath, link)
+				_ = removeFileIfExist(dstPath)
+				err = os.Symlink(link, dstPath)
+				if err != nil {
+					return err
+				}
+				*copied = append(*copied, relPath)
+				continue
+			}
+
+			info, err = os.Stat(srcPath)
+			if err != nil {
+				return fmt.Errorf("broken symbolic link %s : %s", srcPath, err.Error())
+			}
+		}
+
+		if info.IsDir() {
+			err = copyDirectory(srcPath, dstPath, relPath, followSymlink, skip, visited, copied)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("unsupported file type : %s", srcPath)
+		}
+
+		err = removeFileIfExist(dstPath)
+		if err != nil {
+			return err
+		}
+		err = CopyFile(srcPath, dstPath)
+		if err != nil {
+			return err
+		}
+		err = os.Chmod(dstPath, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		*copied = append(*copied, relPath)
+	}
+
+	return nil
+}
+
+// removeFileIfExist 디렉토리가 아닌 파일(또는 심볼릭 링크)이 존재하면 삭제한다
+func removeFileIfExist(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is directory", path)
+	}
+	return os.Remove(path)
+}
+
 func ExecuteCommand(wd, command string) (string, error) {
 	if len(command) == 0 {
 		return "", errors.New("empty command")
@@ -239,6 +378,16 @@ func ExecuteCommand(wd, command string) (string, error) {
 	return out.String(), nil
 }
 
+var shellSafePattern = regexp.MustCompile(`^[A-Za-z0-9_./,:=+@%-]+$`)
+
+// shellQuote 쉘 커맨드의 인자로 사용할 수 있도록 value 를 작은따옴표로 감싼다
+func shellQuote(value string) string {
+	if shellSafePattern.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
 func ExecuteShell(wd, command string) (string, error) {
 	if len(command) == 0 {
 		return "", errors.New("empty command")
@@ -261,12 +410,13 @@ func ExecuteShell(wd, command string) (string, error) {
 type fileMeta struct {
 	Path  string
 	IsDir bool
+	Mode  os.FileMode
 }
 
 func ZipArtifact(baseDir, artifactFile string) error {
 	var files []fileMeta
 	err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
-		files = append(files, fileMeta{Path: path, IsDir: info.IsDir()})
+		files = append(files, fileMeta{Path: path, IsDir: info.IsDir(), Mode: info.Mode()})
 		return nil
 	})
 	if err != nil {
@@ -311,10 +461,34 @@ func copyIntoZip(zw *zip.Writer, path string, f fileMeta) error {
 		Modified: time.Now(),
 	}
 
+	if !f.IsDir && f.Mode.IsRegular() {
+		// 리소스 파일의 권한을 유지한다
+		header.SetMode(f.Mode.Perm())
+	}
+
 	// check platform support relative file
 	if strings.HasPrefix(path, fmt.Sprintf("/%s", PlatformDirName)) {
 		// platform support binary file should be set execute mode
 		header.SetMode(0755)
+		if isWindowsPlatformPath(path) {
+			// windows 플랫폼 파일은 unix 권한 없이 기본(FAT) 헤더로 저장한다
+			header = &zip.FileHeader{Name: path, Method: zip.Deflate, Modified: header.Modified}
+		}
+	}
+
+	if f.Mode&os.ModeSymlink != 0 {
+		// symbolic link 는 링크 대상 경로를 내용으로 저장한다
+		header.SetMode(os.ModeSymlink | 0777)
+		link, err := os.Readlink(f.Path)
+		if err != nil {
+			return err
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(link))
+		return err
 	}
 
 	w, err := zw.CreateHeader(header)
@@ -336,6 +510,12 @@ func copyIntoZip(zw *zip.Writer, path string, f fileMeta) error {
 	return err
 }
 
+// isWindowsPlatformPath far 내부 경로가 /platform/windows_<arch> 하위인지 여부
+func isWindowsPlatformPath(path string) bool {
+	rel := strings.TrimPrefix(filepath.ToSlash(path), "/"+PlatformDirName+"/")
+	return strings.HasPrefix(rel, "windows_")
+}
+
 func EnsureDirectory(dir string) error {
 	if _, err := os.Stat(dir); err != nil {
 		if os.IsNotExist(err) {
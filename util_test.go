@@ -0,0 +1,32 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 5:20
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIsWindowsPlatformPath(t *testing.T) {
+	assert.True(t, isWindowsPlatformPath("/platform/windows_amd64/hello.exe"))
+	assert.False(t, isWindowsPlatformPath("/platform/linux_amd64/hello"))
+	assert.False(t, isWindowsPlatformPath("/windows_amd64/hello"))
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 8:20
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var yamlErrorLinePattern = regexp.MustCompile(`line (\d+): `)
+
+// validateStructuredResources 작업 디렉토리의 xml, json, yaml(yml) 리소스가 올바른 형식인지 검사한다
+func (b *BuildContext) validateStructuredResources() error {
+	if b.project.Resource.SkipValidation {
+		return nil
+	}
+
+	fmt.Printf("\n>> validating xml/json/yaml resources...\n")
+	errList := make([]string, 0)
+	err := filepath.Walk(b.workingDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(b.workingDir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if skipPlatformFile(rel, b.platformResources) {
+			return nil
+		}
+		if rel == deploymentFilename {
+			return nil
+		}
+
+		var validate func([]byte) error
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".xml":
+			validate = validateXml
+		case ".json":
+			validate = validateJson
+		case ".yaml", ".yml":
+			validate = validateYaml
+		default:
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		err = validate(data)
+		if err != nil {
+			errList = append(errList, fmt.Sprintf("%s:%s", rel, err.Error()))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(errList) > 0 {
+		return fmt.Errorf("invalid resource\n%s", strings.Join(errList, "\n"))
+	}
+	return nil
+}
+
+// validateXml well-formed xml 인지 검사한다. 에러는 "line : message" 형태이다
+func validateXml(data []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	foundRoot := false
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var syntaxError *xml.SyntaxError
+			if errors.As(err, &syntaxError) {
+				return fmt.Errorf("%d : %s", syntaxError.Line, syntaxError.Msg)
+			}
+			return fmt.Errorf("%d : %s", lineOfOffset(data, decoder.InputOffset()), err.Error())
+		}
+		// root 엘리먼트가 닫힌 이후에는 주석, 처리 명령, 공백 외의 내용이 올 수 없다
+		switch t := token.(type) {
+		case xml.StartElement:
+			if foundRoot && depth == 0 {
+				return fmt.Errorf("%d : multiple root elements", lineOfOffset(data, decoder.InputOffset()))
+			}
+			foundRoot = true
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(t)) > 0 {
+				return fmt.Errorf("%d : unexpected data outside of root element", lineOfOffset(data, decoder.InputOffset()))
+			}
+		}
+	}
+
+	if !foundRoot {
+		return fmt.Errorf("1 : root element not found")
+	}
+	return nil
+}
+
+func validateJson(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var v interface{}
+	err := decoder.Decode(&v)
+	if err == nil {
+		// 하나의 json 값 뒤에 다른 내용이 있으면 안된다
+		_, err = decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			return fmt.Errorf("%d : unexpected data after top-level value", lineOfOffset(data, decoder.InputOffset()))
+		}
+	}
+
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) {
+		return fmt.Errorf("%d : %s", lineOfOffset(data, syntaxError.Offset), syntaxError.Error())
+	}
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return fmt.Errorf("%d : %s", lineOfOffset(data, typeError.Offset), typeError.Error())
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%d : unexpected end of json", lineOfOffset(data, int64(len(data))))
+	}
+	return fmt.Errorf("%d : %s", lineOfOffset(data, decoder.InputOffset()), err.Error())
+}
+
+func validateYaml(data []byte) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var v interface{}
+		err := decoder.Decode(&v)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			msg := strings.TrimPrefix(err.Error(), "yaml: ")
+			match := yamlErrorLinePattern.FindStringSubmatch(msg)
+			if match != nil {
+				return fmt.Errorf("%s : %s", match[1], strings.Replace(msg, match[0], "", 1))
+			}
+			return fmt.Errorf("1 : %s", msg)
+		}
+	}
+}
+
+// lineOfOffset data 의 offset 위치의 라인번호(1 부터 시작)를 구한다
+func lineOfOffset(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// resolveProcType 프로젝트 설정에 프로세스 타입이 지정되어 있으면 해당 타입을 사용하고,
+// 그렇지 않으면 <process>.ui.xml 파일 존재 여부로 결정한다
+func (b *BuildContext) resolveProcType() (string, error) {
+	procType := strings.ToUpper(b.project.Process.Type)
+	switch procType {
+	case "":
+		return determineProcType(b.workingDir, b.ExposeProcessName), nil
+	case procTypeGeneral:
+		return procType, nil
+	case procTypeUI:
+		uiProcXml := filepath.Join(b.workingDir, fmt.Sprintf("%s.ui.xml", b.ExposeProcessName))
+		if CheckFileExist(uiProcXml) != nil {
+			fmt.Fprintf(os.Stderr, "warning : process type is %s but %s not found\n", procTypeUI, filepath.Base(uiProcXml))
+		}
+		return procType, nil
+	}
+
+	return "", fmt.Errorf("invalid process type %s (%s or %s)", b.project.Process.Type, procTypeGeneral, procTypeUI)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 18. 오후 8:45
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateStructuredResource(t *testing.T) {
+	assert.Nil(t, validateXml([]byte("<?xml version=\"1.0\"?>\n<process>\n  <menu/>\n</process>\n")))
+	err := validateXml([]byte("<process>\n  <menu>\n</process>\n"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "3 : ")
+	assert.NotNil(t, validateXml([]byte("")))
+	assert.Nil(t, validateXml([]byte("<process/>\n<!-- end -->\n")))
+	err = validateXml([]byte("<process/>\n<process/>\n"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "multiple root elements")
+	assert.NotNil(t, validateXml([]byte("<process/>\ntrailing\n")))
+
+	assert.Nil(t, validateJson([]byte("{\n  \"a\": [1, 2]\n}\n")))
+	err = validateJson([]byte("{\n  \"a\": 1,\n  \"b\": }\n"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "3 : ")
+	assert.NotNil(t, validateJson([]byte("{} {}")))
+
+	assert.Nil(t, validateYaml([]byte("a: 1\n---\nb: 2\n")))
+	err = validateYaml([]byte("a: 1\nb: [1, 2\nc: 3\n"))
+	assert.NotNil(t, err)
+	assert.Regexp(t, "^[0-9]+ : ", err.Error())
+}
@@ -0,0 +1,325 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 4:25
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	goWorkFilename = "go.work"
+	goModFilename  = "go.mod"
+)
+
+// GoWorkspace go.work 로 구성된 multi module workspace
+type GoWorkspace struct {
+	File    string
+	Dir     string
+	Modules []WorkspaceModule
+}
+
+// WorkspaceModule go.work 의 use 디렉티브로 지정된 모듈
+type WorkspaceModule struct {
+	Dir  string
+	Path string
+}
+
+// findGoWorkspace go 커맨드와 동일하게 GOWORK 환경변수 또는 dir 로부터 상위로 go.work 파일을 찾는다
+// workspace 가 아니면 nil 을 반환한다
+func findGoWorkspace(dir string) (*GoWorkspace, error) {
+	goWork := os.Getenv("GOWORK")
+	if goWork == "off" {
+		return nil, nil
+	}
+	if len(goWork) > 0 {
+		return loadGoWorkspace(goWork)
+	}
+
+	for {
+		path := filepath.Join(dir, goWorkFilename)
+		if CheckFileExist(path) == nil {
+			return loadGoWorkspace(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// loadGoWorkspace go work edit -json 으로 go.work 파일의 use 모듈 목록을 구한다
+func loadGoWorkspace(file string) (*GoWorkspace, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("go", "work", "edit", "-json", file)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("fail to read %s : %s\n%s", file, err.Error(), stderr.String())
+	}
+
+	var work struct {
+		Use []struct {
+			DiskPath string
+		}
+	}
+	err = json.Unmarshal(stdout.Bytes(), &work)
+	if err != nil {
+		return nil, fmt.Errorf("invalid go work edit output : %s", err.Error())
+	}
+
+	ws := &GoWorkspace{File: file, Dir: filepath.Dir(file), Modules: make([]WorkspaceModule, 0)}
+	for _, use := range work.Use {
+		dir := use.DiskPath
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(ws.Dir, dir)
+		}
+		dir = filepath.Clean(dir)
+		modulePath, err := readModulePath(filepath.Join(dir, goModFilename))
+		if err != nil {
+			return nil, err
+		}
+		ws.Modules = append(ws.Modules, WorkspaceModule{Dir: dir, Path: modulePath})
+	}
+	return ws, nil
+}
+
+// readModulePath go.mod 파일의 module 디렉티브 값을 구한다
+func readModulePath(goModFile string) (string, error) {
+	data, err := os.ReadFile(goModFile)
+	if err != nil {
+		return "", fmt.Errorf("fail to read %s : %s", goModFile, err.Error())
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`"), nil
+		}
+	}
+	return "", fmt.Errorf("module directive not found in %s", goModFile)
+}
+
+// ResolveModule procName 프로세스를 빌드할 workspace 모듈을 결정한다
+//  1. 프로젝트 설정의 processes.<name>.module (go.work 디렉토리로부터의 상대경로 또는 모듈 경로)
+//  2. 현재 디렉토리를 포함하는 모듈 (go.work 디렉토리 자체인 모듈은 제외)
+//  3. 디렉토리명 또는 모듈 경로의 마지막 요소가 procName 인 모듈
+//  4. procName 바이너리를 가지거나 .gofar.yaml 의 processes 에 procName 을 정의한 유일한 모듈
+//  5. go.work 디렉토리 자체인 모듈
+func (w *GoWorkspace) ResolveModule(procName, currentWd, configModule string) (WorkspaceModule, error) {
+	if len(configModule) > 0 {
+		for _, module := range w.Modules {
+			if module.Path == configModule || module.Dir == filepath.Join(w.Dir, configModule) {
+				return module, nil
+			}
+		}
+		return WorkspaceModule{}, fmt.Errorf("module %s is not used in %s", configModule, w.File)
+	}
+
+	var found *WorkspaceModule
+	for i, module := range w.Modules {
+		if module.Dir == w.Dir || !isSubPath(module.Dir, currentWd) {
+			continue
+		}
+		// 중첩된 모듈은 가장 깊은 모듈을 선택한다
+		if found == nil || len(module.Dir) > len(found.Dir) {
+			found = &w.Modules[i]
+		}
+	}
+	if found != nil {
+		return *found, nil
+	}
+
+	for _, module := range w.Modules {
+		if filepath.Base(module.Dir) == procName || filepath.Base(module.Path) == procName {
+			return module, nil
+		}
+	}
+
+	candidates := make([]WorkspaceModule, 0)
+	for _, module := range w.Modules {
+		if moduleHostsProcess(module, procName) {
+			candidates = append(candidates, module)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	if len(candidates) > 1 {
+		paths := make([]string, 0, len(candidates))
+		for _, module := range candidates {
+			paths = append(paths, module.Path)
+		}
+		return WorkspaceModule{}, fmt.Errorf("process %s is ambiguous in workspace (%s). set processes.%s.module in %s",
+			procName, strings.Join(paths, ","), procName, ProjectConfigFile)
+	}
+
+	for _, module := range w.Modules {
+		if module.Dir == w.Dir {
+			return module, nil
+		}
+	}
+	return WorkspaceModule{}, fmt.Errorf("cannot find module for process %s in %s", procName, w.File)
+}
+
+func moduleHostsProcess(module WorkspaceModule, procName string) bool {
+	config, err := loadProjectConfig(module.Dir)
+	if err == nil {
+		if _, ok := config.Processes[procName]; ok {
+			return true
+		}
+	}
+
+	records, err := discoverMainPackages(module.Dir)
+	if err != nil {
+		return false
+	}
+	for _, record := range records {
+		if record.GetBinaryname() == procName {
+			return true
+		}
+	}
+	return false
+}
+
+func isSubPath(baseDir, path string) bool {
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// determineWorkspace 현재 디렉토리가 go.work workspace 에 속하면 프로세스를 빌드할 모듈 디렉토리를 프로젝트 base 디렉토리로 사용한다
+// 모듈 디렉토리에 .gofar.yaml 이 없으면 기존 프로젝트 설정을 그대로 사용한다
+func determineWorkspace(ctx *BuildContext) error {
+	currentWd, _ := os.Getwd()
+	ws, err := findGoWorkspace(currentWd)
+	if err != nil {
+		return err
+	}
+	if ws == nil {
+		return nil
+	}
+
+	module, err := ws.ResolveModule(ctx.ExposeProcessName, currentWd, ctx.project.Processes[ctx.ExposeProcessName].Module)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("workspace : %s\n", ws.File)
+	fmt.Printf("workspace module : %s (%s)\n", module.Path, module.Dir)
+
+	ctx.workspace = ws
+	ctx.workspaceModule = module
+	if module.Dir == ctx.ProjectBaseDir {
+		return nil
+	}
+
+	ctx.ProjectBaseDir = module.Dir
+	if CheckFileExist(filepath.Join(module.Dir, ProjectConfigFile)) == nil {
+		ctx.project, err = loadProjectConfig(module.Dir)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workspaceInfo deployment.json 에 기록할 workspace 정보
+// 바이너리가 사용하는 모듈별로 workspace 모듈은 디렉토리를, 그 외 모듈은 workspace 에서 결정된 버전을 기록한다
+func (b *BuildContext) workspaceInfo() (map[string]interface{}, error) {
+	info := make(map[string]interface{})
+	info["file"] = b.workspace.File
+	if len(b.gitBaseDir) > 0 {
+		if rel, err := filepath.Rel(b.gitBaseDir, b.workspace.File); err == nil {
+			info["file"] = filepath.ToSlash(rel)
+		}
+	}
+	info["module"] = b.workspaceModule.Path
+
+	modules, err := listDependencyModules(b.ProjectBaseDir, b.ProcessList)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceDirs := make(map[string]string)
+	for _, module := range b.workspace.Modules {
+		rel, err := filepath.Rel(b.workspace.Dir, module.Dir)
+		if err != nil {
+			rel = module.Dir
+		}
+		workspaceDirs[module.Path] = "workspace:" + filepath.ToSlash(rel)
+	}
+	for path := range modules {
+		if dir, ok := workspaceDirs[path]; ok {
+			modules[path] = dir
+		}
+	}
+	info["modules"] = modules
+	return info, nil
+}
+
+// listDependencyModules go list -deps 로 main 패키지들이 사용하는 모듈과 버전 목록을 구한다
+// replace 된 모듈은 "version => path version" 형태로 기록한다
+func listDependencyModules(baseDir string, cmdList []CmdRecord) (map[string]string, error) {
+	modules := make(map[string]string)
+	if len(cmdList) == 0 {
+		return modules, nil
+	}
+
+	format := "{{with .Module}}{{.Path}} {{.Version}}{{with .Replace}} => {{.Path}} {{.Version}}{{end}}{{end}}"
+	out, err := goListDeps(baseDir, cmdList, format)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(out, "\n")
+	sort.Strings(lines)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		idx := strings.IndexByte(line, ' ')
+		if idx < 0 {
+			modules[line] = ""
+			continue
+		}
+		modules[line[:idx]] = strings.TrimSpace(line[idx+1:])
+	}
+	return modules, nil
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author dave_01
+ * @date 26. 10. 19. 오후 5:00
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoWorkspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	t.Setenv("GOFLAGS", "")
+	mainSource := "package main\n\nimport _ \"example.com/lib\"\n\nfunc main() {}\n"
+	baseDir := t.TempDir()
+	writeTestSources(t, baseDir, map[string]string{
+		"go.work":                        "go 1.18\n\nuse (\n\t./lib\n\t./services/order\n\t./services/billing\n)\n",
+		"lib/go.mod":                     "module example.com/lib\n\ngo 1.18\n",
+		"lib/lib.go":                     "package lib\n",
+		"services/order/go.mod":          "module example.com/order\n\ngo 1.18\n\nrequire example.com/lib v0.0.0\n",
+		"services/order/cmd/api/a.go":    mainSource,
+		"services/billing/go.mod":        "module example.com/billing\n\ngo 1.18\n",
+		"services/billing/cmd/bill/b.go": "package main\n\nfunc main() {}\n",
+		"services/billing/.gofar.yaml":   "processes:\n  invoice: {}\n",
+	})
+
+	ws, err := findGoWorkspace(filepath.Join(baseDir, "services"))
+	assert.Nil(t, err)
+	assert.NotNil(t, ws)
+	assert.Equal(t, filepath.Join(baseDir, "go.work"), ws.File)
+	assert.Equal(t, 3, len(ws.Modules))
+	assert.Equal(t, "example.com/order", ws.Modules[1].Path)
+
+	orderDir := filepath.Join(baseDir, "services", "order")
+	billingDir := filepath.Join(baseDir, "services", "billing")
+
+	module, err := ws.ResolveModule("anything", filepath.Join(orderDir, "cmd"), "")
+	assert.Nil(t, err)
+	assert.Equal(t, orderDir, module.Dir)
+
+	module, err = ws.ResolveModule("order", baseDir, "")
+	assert.Nil(t, err)
+	assert.Equal(t, orderDir, module.Dir)
+
+	module, err = ws.ResolveModule("api", baseDir, "")
+	assert.Nil(t, err)
+	assert.Equal(t, orderDir, module.Dir)
+
+	module, err = ws.ResolveModule("invoice", baseDir, "")
+	assert.Nil(t, err)
+	assert.Equal(t, billingDir, module.Dir)
+
+	module, err = ws.ResolveModule("api", baseDir, "services/billing")
+	assert.Nil(t, err)
+	assert.Equal(t, billingDir, module.Dir)
+
+	_, err = ws.ResolveModule("unknown", baseDir, "")
+	assert.NotNil(t, err)
+
+	records, err := discoverMainPackages(orderDir)
+	assert.Nil(t, err)
+	modules, err := listDependencyModules(orderDir, records)
+	assert.Nil(t, err)
+	assert.Contains(t, modules, "example.com/lib")
+	assert.Contains(t, modules, "example.com/order")
+}